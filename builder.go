@@ -28,19 +28,27 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package cfgbuild
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/bits"
+	"log"
+	"log/slog"
+	"net"
 	"net/url"
 	"os"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
+
+	"github.com/NathanBak/cfgbuild/internal/timelayout"
 )
 
 // NewConfig will create and initialize a Config of the provided type.
@@ -67,12 +75,18 @@ const (
 // method.
 type Builder[T interface{}] struct {
 	cfg          T
+	ctx          context.Context
 	instantiated bool
-	setProps     map[string]bool
-	debug        bool
-	throwPanics  bool
-	indent       string
-	prefix       string
+	// setProps records the env var name used to set each field (or ">" for nested configs)
+	setProps    map[string]string
+	debug       bool
+	throwPanics bool
+	nested      bool
+	// rootPrefix is the Prefix of the root Builder (used by nested Builders)
+	rootPrefix string
+	// Prefix is prepended to the environment variable names of all fields (including the fields
+	// of nested configs).  Default is no prefix.
+	Prefix string
 	// ListSeparator splits items in a list (slice).  Default is comma (,).
 	ListSeparator string
 	// TagKey used to identify the field tag value to be used.  Default is "envvar".
@@ -87,8 +101,87 @@ type Builder[T interface{}] struct {
 	// just look for "PREFIX_KEY", but if PrefixFallback is set to true and there is no "PREFIX_KEY"
 	// environment variable than it will fall back to "KEY".
 	PrefixFallback bool
+	// EmptyPolicy determines how environment variables that are set to an empty string are
+	// handled.  The default is EmptyAsValue.  The policy can be overridden for a field using the
+	// "notempty", "allowEmpty", and "unsetIfEmpty" tag attributes.
+	EmptyPolicy EmptyPolicy
+	// Sources provide the values for the environment variable names.  The Sources are checked in
+	// order and the first value found is used.  If no Sources are set then the process
+	// environment variables are used.
+	Sources []Source
+	// Strict determines what happens when there are variables which have a prefix used by the
+	// config (either the root Prefix or the prefix of a nested config) but are not used by any
+	// field.  This helps catch typos in variable names.  The default is StrictOff.  Only Sources
+	// which implement ListableSource are checked.
+	Strict StrictMode
+	// OnWarning is called with non-fatal problems found while building (such as unknown
+	// variables when Strict is StrictWarn).  If not set, warnings are written using the standard
+	// log package.
+	OnWarning func(error)
+	// Logger receives structured debug events for each field lookup, default value, and hook
+	// invocation (with the values of "secret" fields redacted).  Warnings are also logged if
+	// OnWarning is not set.  Default is no logging.
+	Logger *slog.Logger
+	// Parsers are used to parse the values of fields with types which aren't otherwise supported
+	// (or to replace the built-in parsing of a type).  The parser for a type is also used for
+	// the items of slices, the keys and values of maps, and the value of pointers of the type.
+	// The value returned by a parser must be assignable to the type.
+	Parsers map[reflect.Type]func(string) (any, error)
+	// Decoders are named functions which transform a value before it is parsed.  A field uses a
+	// decoder by setting the "parser" tag attribute to the name of the decoder (ie
+	// "parser=base64").  Decoders with the same name as a built-in decoder replace it.
+	Decoders map[string]func(string) (string, error)
+	// BoolSyntax determines the values accepted for bool fields (and *bool and []bool fields).
+	// The default is BoolStrict.
+	BoolSyntax BoolSyntax
+	// BasePrefixes allows integer values to have a base prefix (0x or 0X for hexadecimal, 0o,
+	// 0O, or 0 for octal, and 0b or 0B for binary) and underscores between digits (ie
+	// 1_000_000).  The default is false meaning integers are decimal.  The base can also be set
+	// for a field using the "base" tag attribute.
+	BasePrefixes bool
 }
 
+// A StrictMode determines how unknown variables are handled.
+type StrictMode int
+
+const (
+	// StrictOff does not check for unknown variables.
+	StrictOff StrictMode = iota
+	// StrictWarn passes an *UnknownVarsError to the Builder OnWarning function.
+	StrictWarn
+	// StrictError causes Build() to return an *UnknownVarsError.
+	StrictError
+)
+
+// An EmptyPolicy determines how an environment variable which is set to an empty string is
+// handled.
+type EmptyPolicy int
+
+const (
+	// EmptyAsValue treats an empty string like any other value.  It is parsed for the field type
+	// (which fails for types such as numbers) and counts as being set for "required" fields.
+	EmptyAsValue EmptyPolicy = iota
+	// EmptyAsUnset treats an empty string as if the environment variable was not set so the
+	// default value applies and "required" fields are reported as missing.
+	EmptyAsUnset
+	// EmptyAsError causes Build() to return an error if the environment variable is empty.
+	EmptyAsError
+	// EmptyAsZero treats an empty string as a legitimate value and sets the field to the zero
+	// value for its type.  It counts as being set for "required" fields.
+	EmptyAsZero
+)
+
+// A BoolSyntax determines the values accepted for bool fields.
+type BoolSyntax int
+
+const (
+	// BoolStrict accepts "true" and "false" (case insensitive).
+	BoolStrict BoolSyntax = iota
+	// BoolLenient accepts the values accepted by strconv.ParseBool along with "yes", "no", "on",
+	// and "off" (all case insensitive).
+	BoolLenient
+)
+
 type initInterface interface {
 	CfgBuildInit() error
 }
@@ -97,9 +190,28 @@ type validateInterface interface {
 	CfgBuildValidate() error
 }
 
+type initContextInterface interface {
+	CfgBuildInitContext(ctx context.Context) error
+}
+
+type validateContextInterface interface {
+	CfgBuildValidateContext(ctx context.Context) error
+}
+
+// Build creates and initializes a new Config.
 func (b *Builder[T]) Build() (cfg T, err error) {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+	return b.BuildContext(context.Background())
+}
+
+// BuildContext creates and initializes a new Config.  The context is passed to the Sources and to
+// the CfgBuildInitContext() and CfgBuildValidateContext() functions of the Config (if they exist).
+// If the context is canceled or times out, the build stops and the returned error wraps the
+// context error.
+func (b *Builder[T]) BuildContext(ctx context.Context) (cfg T, err error) {
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
+
+	b.ctx = ctx
 
 	if !b.throwPanics {
 		// Don't Panic!
@@ -114,16 +226,27 @@ func (b *Builder[T]) Build() (cfg T, err error) {
 	if err != nil {
 		return b.cfg, err
 	}
-	b.printDebugf("building type %T", b.cfg)
+	b.logDebug("building config", "type", fmt.Sprintf("%T", b.cfg), "prefix", b.Prefix)
 
 	err = b.validateCfgTags()
 	if err != nil {
 		return b.cfg, err
 	}
 
-	// If config has CfgBuildInit() function, run it.
-	initter, ok := any(b.cfg).(initInterface)
-	if ok {
+	err = b.checkContext()
+	if err != nil {
+		return b.cfg, err
+	}
+
+	// If config has CfgBuildInitContext() or CfgBuildInit() function, run it.
+	if initter, ok := any(b.cfg).(initContextInterface); ok {
+		b.logDebug("invoking hook", "hook", "CfgBuildInitContext")
+		err = initter.CfgBuildInitContext(ctx)
+		if err != nil {
+			return b.cfg, err
+		}
+	} else if initter, ok := any(b.cfg).(initInterface); ok {
+		b.logDebug("invoking hook", "hook", "CfgBuildInit")
 		err = initter.CfgBuildInit()
 		if err != nil {
 			return b.cfg, err
@@ -135,7 +258,7 @@ func (b *Builder[T]) Build() (cfg T, err error) {
 		return b.cfg, err
 	}
 
-	b.setProps = make(map[string]bool)
+	b.setProps = make(map[string]string)
 
 	err = b.readEnvVars()
 	if err != nil {
@@ -147,216 +270,439 @@ func (b *Builder[T]) Build() (cfg T, err error) {
 		return b.cfg, err
 	}
 
-	// If config has a CfgBuildValidate() function, run it.
-	validator, ok := any(b.cfg).(validateInterface)
-	if ok {
+	if !b.nested {
+		err = b.checkUnknownVars()
+		if err != nil {
+			return b.cfg, err
+		}
+	}
+
+	err = b.checkContext()
+	if err != nil {
+		return b.cfg, err
+	}
+
+	// If config has a CfgBuildValidateContext() or CfgBuildValidate() function, run it.
+	if validator, ok := any(b.cfg).(validateContextInterface); ok {
+		b.logDebug("invoking hook", "hook", "CfgBuildValidateContext")
+		err = validator.CfgBuildValidateContext(ctx)
+	} else if validator, ok := any(b.cfg).(validateInterface); ok {
+		b.logDebug("invoking hook", "hook", "CfgBuildValidate")
 		err = validator.CfgBuildValidate()
 	}
 	return b.cfg, err
 }
 
-func (b *Builder[T]) validateCfgTags() error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+// checkContext returns an error wrapping the context error if the context is done.
+func (b *Builder[T]) checkContext() error {
+	if err := b.ctx.Err(); err != nil {
+		return fmt.Errorf("build stopped (%w)", err)
+	}
+	return nil
+}
 
-	typ := reflect.TypeOf(b.cfg).Elem()
+func (b *Builder[T]) validateCfgTags() error {
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		fieldName := field.Name
-		tagValue, ok := field.Tag.Lookup(b.getTagKey())
+	// The root Builder validates the tags of the whole config tree before any hooks are run so
+	// the nested Builders only need to check their own fields.
+	if b.nested {
+		return b.plan(b.cfgType()).err
+	}
+	return b.validateTypeTags(b.cfgType(), nil)
+}
 
-		// Ignore field if tag value isn't set
-		if !ok {
-			continue
-		}
+// validateTypeTags returns the first tag syntax error found in the fields of the struct type typ
+// or in the fields of its nested configs.  The parents are the types of the configs containing typ
+// and are used to detect recursive nested configs (which would otherwise be built forever).
+func (b *Builder[T]) validateTypeTags(typ reflect.Type, parents []reflect.Type) error {
+	p := b.plan(typ)
+	if p.err != nil {
+		return p.err
+	}
 
-		// Tags may not be set on non-public fields
-		if !isPublicField(field) {
-			msg := "non-public fields may not have the tag set"
+	parents = append(parents, typ)
+	for _, f := range p.fields {
+		if f.parser != "" && b.decoder(f.parser) == nil {
 			return &TagSyntaxError{
-				FieldName: fieldName,
+				FieldName: f.field.Name,
 				TagKey:    b.getTagKey(),
-				TagValue:  tagValue,
-				msg:       msg,
+				TagValue:  f.tagValue,
+				msg:       fmt.Sprintf("unknown parser %q", f.parser),
 			}
 		}
 
-		envVarName := getTagEnvVarName(tagValue)
+		if f.name != ">" {
+			continue
+		}
 
-		if envVarName == "" {
-			msg := "tag does not have the name attribute set"
+		nestedTyp := nestedConfigType(f.field.Type)
+		if slices.Contains(parents, nestedTyp) {
 			return &TagSyntaxError{
-				FieldName: fieldName,
+				FieldName: f.field.Name,
 				TagKey:    b.getTagKey(),
-				TagValue:  tagValue,
-				msg:       msg,
+				TagValue:  f.tagValue,
+				msg:       fmt.Sprintf("nested config type %s is recursive", nestedTyp),
 			}
 		}
 
-		_, defaultSet := getTagAttribute(tagValue, tagAttrDefault)
-		if envVarName == ">" && defaultSet {
-			msg := "the \"default\" attribute is not allowed on \">\" nested config fields"
-			return &TagSyntaxError{
-				FieldName: fieldName,
-				TagKey:    b.getTagKey(),
-				TagValue:  tagValue,
-				msg:       msg,
-			}
+		err := b.validateTypeTags(nestedTyp, parents)
+		if err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		_, requiredSet := getTagAttribute(tagValue, tagAttrRequired)
-		if envVarName == "-" && requiredSet {
-			msg := "the \"required\" attribute is not allowed on \"-\" fields"
-			return &TagSyntaxError{
-				FieldName: fieldName,
-				TagKey:    b.getTagKey(),
-				TagValue:  tagValue,
-				msg:       msg,
-			}
+// rootPrefixValue returns the Prefix of the root Builder.
+func (b *Builder[T]) rootPrefixValue() string {
+	if b.nested {
+		return b.rootPrefix
+	}
+	return b.Prefix
+}
+
+// nestedConfigType returns the struct type of a ">" nested config field of type typ.
+func nestedConfigType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// validateFieldPlan returns a TagSyntaxError if there is a problem with the tag of the field.
+func validateFieldPlan(f *fieldPlan, tagKey string) error {
+	syntaxError := func(msg string) error {
+		return &TagSyntaxError{
+			FieldName: f.field.Name,
+			TagKey:    tagKey,
+			TagValue:  f.tagValue,
+			msg:       msg,
 		}
+	}
 
-		_, marshalJSONSet := getTagAttribute(tagValue, tagAttrUnmarshalJSON)
-		if marshalJSONSet {
-			value := reflect.ValueOf(b.cfg).Elem()
-			fieldVal := value.Field(i)
-			fieldInterface := fieldVal.Addr().Interface()
-			err := json.Unmarshal([]byte("{}"), fieldInterface)
-			if err != nil {
-				msg := "field type does not support \"unmarshalJSON\" tag attribute"
-				return &TagSyntaxError{
-					FieldName: fieldName,
-					TagKey:    b.getTagKey(),
-					TagValue:  tagValue,
-					msg:       msg,
-				}
-			}
+	// Tags may not be set on non-public fields
+	if !isPublicField(f.field) {
+		return syntaxError("non-public fields may not have the tag set")
+	}
+
+	if msg := checkTagAttributes(f); msg != "" {
+		return syntaxError(msg)
+	}
+
+	if f.name == ">" && nestedConfigType(f.field.Type).Kind() != reflect.Struct {
+		return syntaxError(`the ">" env var name is only allowed on struct and struct pointer fields`)
+	}
+
+	if _, ok := f.attr(tagAttrLayout); ok && nestedConfigType(f.field.Type) != reflect.TypeOf(time.Time{}) {
+		return syntaxError(`the "layout" attribute is only allowed on time.Time fields`)
+	}
+
+	if _, ok := f.attr(tagAttrUnit); ok && !isIntegerKind(nestedConfigType(f.field.Type).Kind()) {
+		return syntaxError(`the "unit" attribute is only allowed on integer fields`)
+	}
+
+	if f.hostPort {
+		typ := f.field.Type
+		if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
 		}
+		if typ.Kind() != reflect.String {
+			return syntaxError(`the "hostport" attribute is only allowed on string fields`)
+		}
+	}
 
-		_, prefixSet := getTagAttribute(tagValue, tagAttrPrefix)
-		if envVarName != ">" && prefixSet {
-			msg := `the "prefix" attribute is only allowed on ">" nested config fields`
-			return &TagSyntaxError{
-				FieldName: fieldName,
-				TagKey:    b.getTagKey(),
-				TagValue:  tagValue,
-				msg:       msg,
+	if f.keyVar != "" && nestedConfigType(f.field.Type) != reflect.TypeOf(tls.Certificate{}) {
+		return syntaxError(`the "key" attribute is only allowed on tls.Certificate fields`)
+	}
+
+	if f.schemes != nil {
+		typ := f.field.Type
+		if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ != reflect.TypeOf(url.URL{}) {
+			return syntaxError(`the "schemes" attribute is only allowed on url.URL fields`)
+		}
+	}
+
+	if f.hasBase {
+		typ := f.field.Type
+		if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if !isIntegerKind(typ.Kind()) || typ == reflect.TypeOf(time.Duration(0)) {
+			return syntaxError(`the "base" attribute is only allowed on integer fields`)
+		}
+	}
+
+	if f.unmarshalJSON {
+		fieldInterface := reflect.New(f.field.Type).Interface()
+		err := json.Unmarshal([]byte("{}"), fieldInterface)
+		if err != nil {
+			return syntaxError("field type does not support \"unmarshalJSON\" tag attribute")
+		}
+	}
+	return nil
+}
+
+// ValidateTag returns an error if there is a problem with a tag value (such as an unknown
+// attribute or an attribute which isn't allowed with the env var name).  It only performs the
+// checks which don't depend on the field the tag is set on and is intended for tools such as
+// linters and code generators.  Build() performs the same checks.
+func ValidateTag(tagValue string) error {
+	if msg := checkTagAttributes(compileFieldPlan(reflect.StructField{}, tagValue)); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// checkTagAttributes returns a message describing the first problem found with the env var name
+// and attributes of the tag (or an empty string if there are no problems).
+func checkTagAttributes(f *fieldPlan) string {
+	envVarName := f.name
+
+	for _, alias := range f.aliases {
+		if alias == "" || envVarName == "-" || envVarName == ">" {
+			return "tag contains an invalid env var name alias"
+		}
+	}
+
+	if envVarName == "" {
+		return "tag does not have the name attribute set"
+	}
+
+	if envVarName == ">" && f.hasDefault {
+		return "the \"default\" attribute is not allowed on \">\" nested config fields"
+	}
+
+	if envVarName == "-" && f.required {
+		return "the \"required\" attribute is not allowed on \"-\" fields"
+	}
+
+	if _, prefixSet := f.attr(tagAttrPrefix); envVarName != ">" && prefixSet {
+		return `the "prefix" attribute is only allowed on ">" nested config fields`
+	}
+
+	if envVarName == ">" && f.oneOf != nil {
+		return `the "oneof" attribute is not allowed on ">" nested config fields`
+	}
+
+	if base, ok := f.attr(tagAttrBase); ok && (f.base < 0 || f.base == 1 || f.base > 36 ||
+		strconv.Itoa(f.base) != base) {
+		return fmt.Sprintf(`the "base" attribute value %q is not a valid base (0 or 2 to 36)`, base)
+	}
+
+	if f.keyVar != "" && f.hasDefault {
+		return `the "default" and "key" attributes may not be used together`
+	}
+
+	if unit, ok := f.attr(tagAttrUnit); ok && unit != unitBytes {
+		return fmt.Sprintf(`the "unit" attribute value %q is not supported (use %q)`, unit, unitBytes)
+	}
+
+	for _, attr := range []tagAttr{tagAttrSecret, tagAttrStatic, tagAttrParser, tagAttrHostPort,
+		tagAttrSchemes, tagAttrFile, tagAttrKey} {
+		if _, found := f.attr(attr); found && envVarName == ">" {
+			return fmt.Sprintf(`the %q attribute is not allowed on ">" nested config fields`, attr)
+		}
+	}
+
+	emptyAttrs := []string{}
+	for _, attr := range []tagAttr{tagAttrNotEmpty, tagAttrAllowEmpty, tagAttrUnsetIfEmpty} {
+		if _, found := f.attr(attr); found {
+			emptyAttrs = append(emptyAttrs, fmt.Sprintf("%q", attr))
+		}
+	}
+	if len(emptyAttrs) > 0 && (envVarName == ">" || envVarName == "-") {
+		return fmt.Sprintf(`the %s attribute is not allowed on %q fields`, emptyAttrs[0], envVarName)
+	}
+	if len(emptyAttrs) > 1 {
+		return fmt.Sprintf(`the %s attributes may not be used together`, strings.Join(emptyAttrs, " and "))
+	}
+
+	for _, fa := range f.attrs {
+		found := false
+		for _, attr := range allTagAttr {
+			if fa.name == string(attr) {
+				found = true
+				break
 			}
 		}
+		if !found && fa.name != "" {
+			return fmt.Sprintf(`tag value contains non-existent attribute %q`, fa.name)
+		}
+	}
 
-		attrNames := getTagAttributeNames(tagValue)
-		for _, attrName := range attrNames {
-			found := false
-			for _, attr := range allTagAttr {
-				if attrName == string(attr) {
-					found = true
-					break
-				}
+	for _, attr := range allTagAttr {
+		for _, fa := range f.attrs {
+			if fa.name != string(attr) {
+				continue
 			}
-			if !found && attrName != "" {
-				msg := fmt.Sprintf(`tag value contains non-existent attribute %q`, attrName)
-				return &TagSyntaxError{
-					FieldName: fieldName,
-					TagKey:    b.getTagKey(),
-					TagValue:  tagValue,
-					msg:       msg,
-				}
+			if attr.hasValue() && !fa.hasValue {
+				return fmt.Sprintf(`the %q attribute requires a value`, attr)
+			}
+			if !attr.hasValue() && fa.hasValue {
+				return fmt.Sprintf(`the %q attribute may not have a value`, attr)
 			}
 		}
+	}
+	return ""
+}
 
-		for _, attr := range allTagAttr {
-			if _, found := getTagAttribute(tagValue, attr); found {
-				if attr.hasValue() && !strings.Contains(tagValue, string(attr)+"=") {
-					msg := fmt.Sprintf(`the %q attribute requires a value`, attr)
-					return &TagSyntaxError{
-						FieldName: fieldName,
-						TagKey:    b.getTagKey(),
-						TagValue:  tagValue,
-						msg:       msg,
-					}
-				}
+// A taggedField describes a struct field that has the tag key set.
+type taggedField struct {
+	*fieldPlan
+	// prefix is the env var name prefix in effect for the struct containing the field
+	prefix string
+	// path is the dotted list of field names leading to the field (ie Nested.MyVal)
+	path string
+	// index is the sequence of field indexes leading to the field from the walked type
+	index []int
+}
 
-				if !attr.hasValue() && strings.Contains(tagValue, string(attr)+"=") {
-					msg := fmt.Sprintf(`the %q attribute may not have a value`, attr)
-					return &TagSyntaxError{
-						FieldName: fieldName,
-						TagKey:    b.getTagKey(),
-						TagValue:  tagValue,
-						msg:       msg,
-					}
-				}
+// envVarName returns the fully prefixed environment variable name for the field.
+func (f taggedField) envVarName() string {
+	return f.prefix + f.name
+}
+
+// aliasEnvVarNames returns the fully prefixed deprecated alias names for the field.
+func (f taggedField) aliasEnvVarNames() []string {
+	names := []string{}
+	for _, alias := range f.aliases {
+		names = append(names, f.prefix+alias)
+	}
+	return names
+}
+
+// value returns the field's value within the struct value v.  The returned bool is false if the
+// field cannot be reached because a nested config pointer leading to it is nil.
+func (f taggedField) value(v reflect.Value) (reflect.Value, bool) {
+	for _, i := range f.index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return v, false
 			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// walkTaggedFields calls fn for each field of the struct type typ which has the tag key set.
+// Fields are visited in declaration order.  If recurse is true then after a ">" nested config
+// field is visited the fields of the nested config are also visited using the nested prefix.
+// Recursive nested configs are only visited once.
+func (b *Builder[T]) walkTaggedFields(typ reflect.Type, prefix string, recurse bool,
+	fn func(f taggedField) error) error {
+	return b.walkTaggedFieldsPath(typ, prefix, prefix, "", nil, nil, recurse, fn)
+}
+
+// walkTaggedFieldsPath walks the fields of typ.  The root is the prefix of the walked type and the
+// prefix is the prefix in effect for typ.  Nested prefixes don't accumulate, so the prefix of a
+// nested config is always the root followed by the nested prefix.
+func (b *Builder[T]) walkTaggedFieldsPath(typ reflect.Type, root, prefix, path string, index []int,
+	parents []reflect.Type, recurse bool, fn func(f taggedField) error) error {
+
+	parents = append(parents, typ)
+	for _, fp := range b.plan(typ).fields {
+		f := taggedField{
+			fieldPlan: fp,
+			prefix:    prefix,
+			path:      path + fp.field.Name,
+			index:     append(append([]int{}, index...), fp.field.Index...),
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+
+		if !recurse || f.name != ">" {
+			continue
+		}
+
+		nestedTyp := nestedConfigType(fp.field.Type)
+		if nestedTyp.Kind() != reflect.Struct || slices.Contains(parents, nestedTyp) {
+			continue
+		}
+
+		err := b.walkTaggedFieldsPath(nestedTyp, root, root+fp.nestedPrefix, f.path+".", f.index,
+			parents, recurse, fn)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 func (b *Builder[T]) setDefaults() error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 	return b.fieldLoop(true)
 }
 
 func (b *Builder[T]) readEnvVars() error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 	return b.fieldLoop(false)
 }
 
 func (b *Builder[T]) fieldLoop(setDefault bool) error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 
-	typ := reflect.TypeOf(b.cfg).Elem()
 	value := reflect.ValueOf(b.cfg).Elem()
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		fieldName := field.Name
-
-		tagValue, ok := field.Tag.Lookup(b.getTagKey())
-		if !ok {
-			b.printDebugf("skipping %q because it does not have the %q tag set", fieldName,
-				b.getTagKey())
-			continue
-		}
-
-		envVarName := getTagEnvVarName(tagValue)
+	for _, f := range b.plan(value.Type()).fields {
+		fieldName := f.field.Name
+		fieldVal := value.FieldByIndex(f.field.Index)
+		envVarName := f.name
 
 		if !setDefault && envVarName == "-" {
-			b.printDebugf("skipping field %q because env var name is set to \"-\"", fieldName)
+			b.logDebug("skipping field without env var", "field", fieldName)
 			continue
 		}
 
-		defaultVal, defaultAttributeSet := getTagAttribute(tagValue, tagAttrDefault)
-
-		if setDefault && !defaultAttributeSet {
+		if setDefault && !f.hasDefault {
 			continue
 		}
 
 		if envVarName == ">" {
-			myTyp := value.Field(i).Type()
+			myTyp := f.field.Type
 			myNew := reflect.New(myTyp)
 			myVal := myNew.Interface()
 
-			if strings.HasPrefix(myTyp.String(), "*") {
+			if myTyp.Kind() == reflect.Pointer {
 				myVal = myNew.Elem().Interface()
 			}
 
 			cb := Builder[interface{}]{
 				cfg:               myVal,
 				debug:             b.debug,
-				indent:            b.indent,
+				Logger:            b.Logger,
 				ListSeparator:     b.ListSeparator,
 				KeyValueSeparator: b.KeyValueSeparator,
 				TagKey:            b.TagKey,
 				Uint8Lists:        b.Uint8Lists,
 				PrefixFallback:    b.PrefixFallback,
+				EmptyPolicy:       b.EmptyPolicy,
+				Sources:           b.Sources,
+				Strict:            b.Strict,
+				OnWarning:         b.OnWarning,
+				Parsers:           b.Parsers,
+				Decoders:          b.Decoders,
+				BoolSyntax:        b.BoolSyntax,
+				BasePrefixes:      b.BasePrefixes,
+				nested:            true,
 			}
 
-			cb.prefix, _ = getTagAttribute(tagValue, tagAttrPrefix)
+			// The nested prefix replaces (rather than extends) the prefix of this config
+			cb.rootPrefix = b.rootPrefixValue()
+			cb.Prefix = cb.rootPrefix + f.nestedPrefix
 
-			ccfg, err := cb.Build()
+			ccfg, err := cb.BuildContext(b.ctx)
 			if err != nil {
 				return err
 			}
@@ -364,54 +710,102 @@ func (b *Builder[T]) fieldLoop(setDefault bool) error {
 			rvo := reflect.ValueOf(ccfg)
 
 			if len(cb.setProps) > 0 {
-				if strings.HasPrefix(myTyp.String(), "*") {
-					value.Field(i).Set(rvo)
+				if myTyp.Kind() == reflect.Pointer {
+					fieldVal.Set(rvo)
 				} else {
 					ele := rvo.Elem()
-					value.Field(i).Set(ele)
+					fieldVal.Set(ele)
 				}
-				b.setProps[fieldName] = true
+				b.setProps[fieldName] = envVarName
 			} else {
-				b.printDebugf("no properties set for field %q", fieldName)
+				b.logDebug("no values set for nested config", "field", fieldName)
 			}
 		} else {
-			var valStr string
+			var valStr, usedName string
 			if setDefault {
-				valStr = defaultVal
+				valStr = f.defaultVal
 			} else {
-				if envVarVal, ok := os.LookupEnv(b.prefix + envVarName); ok {
-					valStr = envVarVal
-				} else if envVarVal, ok := os.LookupEnv(envVarName); b.PrefixFallback && ok {
-					valStr = envVarVal
-				} else {
+				policy := b.emptyPolicy(f)
+				envVarVal, used, ok, err := b.lookupEnvVar(f.names, policy)
+				if err != nil {
+					return err
+				}
+				if !ok {
 					continue
 				}
+				usedName = used
+
+				if envVarVal == "" && policy == EmptyAsZero {
+					// the empty value must still be one of the allowed values
+					if err := checkOneOf(f.oneOf, envVarVal); err != nil {
+						return &ParseError{Name: b.Prefix + envVarName, Field: fieldName, Err: err}
+					}
+					fieldVal.Set(reflect.Zero(f.field.Type))
+					b.logDebug("set zero value for empty var", "field", fieldName, "key", usedName)
+					b.setProps[fieldName] = usedName
+					continue
+				}
+				valStr = envVarVal
+			}
+
+			parseError := func(err error) error {
+				return &ParseError{Name: b.Prefix + envVarName, Field: fieldName, Default: setDefault, Err: err}
+			}
+
+			// With the "file" attribute the value names the file to read (and the name is logged)
+			logStr := valStr
+			if f.file {
+				contents, err := readFile(valStr)
+				if err != nil {
+					return parseError(err)
+				}
+				valStr = contents
+			}
+
+			if f.keyVar != "" {
+				key, err := b.readKey(f)
+				if err != nil {
+					return err
+				}
+				valStr += "\n" + key
+			}
+
+			if f.parser != "" {
+				decoded, err := b.decoder(f.parser)(valStr)
+				if err != nil {
+					return parseError(err)
+				}
+				valStr = decoded
+			}
+
+			if err := checkOneOf(f.oneOf, valStr); err != nil {
+				return parseError(err)
+			}
+
+			if !f.file {
+				logStr = valStr
 			}
 
-			if _, tagFound := getTagAttribute(tagValue, tagAttrUnmarshalJSON); tagFound {
-				fieldVal := value.Field(i)
+			if f.unmarshalJSON {
 				fieldInterface := fieldVal.Addr().Interface()
 				err := json.Unmarshal([]byte(valStr), fieldInterface)
 				if err != nil {
 					return err
 				}
-				b.printDebugf("unmarshaled value for field %q", field.Name)
+				b.logFieldSet(f, usedName, logStr, setDefault)
 
 				if !setDefault {
-					b.setProps[fieldName] = true
+					b.setProps[fieldName] = usedName
 				}
 			} else {
 
-				err := b.setFieldValue(fieldName, value.Field(i), valStr)
+				err := b.setFieldValue(f, fieldVal, valStr)
 				if err != nil {
-					if setDefault {
-						return fmt.Errorf("error setting default value for %q (%s)", b.prefix+envVarName, err.Error())
-					}
-					return fmt.Errorf("error reading %q (%s)", b.prefix+envVarName, err.Error())
+					return parseError(err)
 				}
-				b.printDebugf("set value for field %q", fieldName)
+				b.logFieldSet(f, usedName, logStr, setDefault)
 				if !setDefault {
-					b.setProps[fieldName] = true
+					b.setProps[fieldName] = usedName
 				}
 			}
 		}
@@ -420,9 +814,167 @@ func (b *Builder[T]) fieldLoop(setDefault bool) error {
 	return nil
 }
 
+// lookupEnvVar returns the value of the first env var found from the provided names (the name
+// followed by any deprecated aliases) along with the name of the env var which was used.  A
+// warning is issued if a deprecated alias is set, and an error is returned if more than one of the
+// names is set and the values differ.
+func (b *Builder[T]) lookupEnvVar(names []string, policy EmptyPolicy) (string, string, bool, error) {
+	var val, used string
+	found := false
+
+	for i, name := range names {
+		v, n, ok, err := b.lookupEnvVarName(name, policy)
+		if err != nil {
+			return "", "", false, err
+		}
+		if !ok {
+			continue
+		}
+
+		if i > 0 {
+			b.warn(&DeprecatedVarError{Name: n, Replacement: b.Prefix + names[0]})
+		}
+
+		if !found {
+			val, used, found = v, n, true
+		} else if v != val {
+			return "", "", false, fmt.Errorf("error reading %q (conflicting values set for %q and %q)",
+				b.Prefix+names[0], used, n)
+		}
+	}
+
+	return val, used, found, nil
+}
+
+// lookupEnvVarName returns the value of the env var with the provided name (with the prefix
+// applied and, if PrefixFallback is set, without the prefix) along with the name of the env var
+// which was used.  The returned bool is false if the env var is not set or is empty and the
+// policy is EmptyAsUnset.  An error is returned if the env var is empty and the policy is
+// EmptyAsError.
+func (b *Builder[T]) lookupEnvVarName(envVarName string, policy EmptyPolicy) (string, string, bool, error) {
+	names := []string{b.Prefix + envVarName}
+	if b.PrefixFallback {
+		names = append(names, envVarName)
+	}
+
+	sources := b.Sources
+	if len(sources) == 0 {
+		sources = []Source{EnvSource{}}
+	}
+
+	for i, name := range names {
+		val, ok, err := b.lookupSources(sources, name)
+		if err != nil {
+			return "", "", false, err
+		}
+		if b.logger() != nil {
+			b.logDebug("looked up var", "key", name, "found", ok, "fallback", i > 0)
+		}
+		if !ok {
+			continue
+		}
+		if val == "" {
+			switch policy {
+			case EmptyAsUnset:
+				b.logDebug("ignoring empty var", "key", name)
+				continue
+			case EmptyAsError:
+				return "", "", false, fmt.Errorf("error reading %q (value may not be empty)", name)
+			}
+		}
+		return val, name, true, nil
+	}
+	return "", "", false, nil
+}
+
+// A ParseError is returned when the value of an env var (or the default value) can't be used to
+// set a field.
+type ParseError struct {
+	// Name is the env var name (including the Builder Prefix).
+	Name string
+	// Field is the name of the struct field.
+	Field string
+	// Default is true if the value was the default value from the tag.
+	Default bool
+	// Err is the reason the value couldn't be used.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Default {
+		return fmt.Sprintf("error setting default value for %q (%s)", e.Name, e.Err.Error())
+	}
+	return fmt.Sprintf("error reading %q (%s)", e.Name, e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// A DeprecatedVarError is passed to the Builder OnWarning function when a deprecated alias of an
+// env var name is set.
+type DeprecatedVarError struct {
+	// Name is the deprecated env var name.
+	Name string
+	// Replacement is the env var name which should be used instead.
+	Replacement string
+}
+
+func (e *DeprecatedVarError) Error() string {
+	return fmt.Sprintf("variable %q is deprecated (use %q instead)", e.Name, e.Replacement)
+}
+
+// lookupSources returns the value from the first of the sources which has a value for the name.
+func (b *Builder[T]) lookupSources(sources []Source, name string) (string, bool, error) {
+	for _, src := range sources {
+		if err := b.ctx.Err(); err != nil {
+			return "", false, fmt.Errorf("error reading %q (%w)", name, err)
+		}
+		val, ok, err := src.Lookup(b.ctx, name)
+		if err != nil {
+			return "", false, fmt.Errorf("error reading %q (%w)", name, err)
+		}
+		if ok {
+			return val, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// emptyPolicy returns the EmptyPolicy for a field based on its tag attributes and the Builder
+// setting.
+func (b *Builder[T]) emptyPolicy(f *fieldPlan) EmptyPolicy {
+	if f.hasEmptyPolicy {
+		return f.emptyPolicy
+	}
+	return b.EmptyPolicy
+}
+
+// fresh returns a copy of the Builder with the same settings which will build a new Config.
+func (b *Builder[T]) fresh() *Builder[T] {
+	var zero T
+	nb := *b
+	nb.cfg = zero
+	nb.instantiated = false
+	nb.setProps = nil
+	return &nb
+}
+
+// cfgType returns the struct type of the config being built.
+func (b *Builder[T]) cfgType() reflect.Type {
+	typ := reflect.TypeOf(b.cfg)
+	if typ == nil {
+		typ = reflect.TypeOf(&b.cfg).Elem()
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
 func (b *Builder[T]) instantiateCfg() error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 	if !b.instantiated {
 		typ := reflect.TypeOf(b.cfg)
 		val := reflect.New(typ.Elem()).Interface().(T)
@@ -432,16 +984,16 @@ func (b *Builder[T]) instantiateCfg() error {
 	return nil
 }
 
-func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string) error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
+// setFieldValue parses s and sets v to the value.  The field's tag attributes (such as "layout")
+// can affect how s is parsed.
+func (b *Builder[T]) setFieldValue(f *fieldPlan, v reflect.Value, s string) error {
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 
-	b.printDebugf("fieldName: %q\n%stype:      %q\n%skind:      %q\n%sstringval: %q\n%s",
-		fieldName, b.indent,
-		v.Type().String(), b.indent,
-		v.Kind().String(), b.indent,
-		s, b.indent,
-	)
+	if b.logger() != nil {
+		b.logDebug("parsing field value", "field", f.field.Name, "type", v.Type().String(),
+			"kind", v.Kind().String())
+	}
 
 	if !v.CanAddr() {
 		return errors.New("unable to obtain field address")
@@ -451,17 +1003,46 @@ func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string)
 		return errors.New("unable to set field value")
 	}
 
+	if rv, ok, err := b.parseRegistered(f, v.Type(), s); ok {
+		if err != nil {
+			return err
+		}
+		v.Set(rv)
+		return nil
+	}
+
+	if f.unit == unitBytes {
+		return setBytes(v, s)
+	}
+
 	sep := b.ListSeparator
 
 	switch v.Type() {
 
-	case reflect.TypeOf(time.Now()): // Time
-		t, err := time.Parse(time.RFC3339, s)
+	case reflect.TypeOf(time.Time{}): // Time
+		t, err := timelayout.Parse(f.layout, s)
 		if err != nil {
 			return err
 		}
 		v.Set(reflect.ValueOf(t))
 
+	case reflect.TypeOf(time.Location{}): // Location
+		loc, err := time.LoadLocation(s)
+		if err != nil {
+			return err
+		}
+		// time.Local is initialized lazily (on first use) so it needs to be used before it's
+		// copied or the copy would be an empty location (which is UTC)
+		_ = loc.String()
+		v.Set(reflect.ValueOf(loc).Elem())
+
+	case reflect.TypeOf(&time.Location{}): // Location pointer (to keep time.UTC and time.Local)
+		loc, err := time.LoadLocation(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(loc))
+
 	case reflect.TypeOf(time.Duration(3)): // Duration
 		i, err := strconv.ParseInt(s, 10, 64)
 		if err != nil {
@@ -478,86 +1059,52 @@ func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string)
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(*u))
-
-	case reflect.TypeOf([]string{}):
-		vals := split(s, sep)
-		v.Set(reflect.ValueOf(vals))
-
-	case reflect.TypeOf([]int{}):
-		vals, err := parseIntegers[int](s, sep, true, bits.UintSize)
-		if err != nil {
-			return err
+		if f.schemes != nil && !slices.Contains(f.schemes, u.Scheme) {
+			return fmt.Errorf("URL scheme %q is not one of %s", u.Scheme, strings.Join(f.schemes, ", "))
 		}
-		v.Set(reflect.ValueOf(vals))
+		v.Set(reflect.ValueOf(*u))
 
-	case reflect.TypeOf([]int8{}):
-		vals, err := parseIntegers[int8](s, sep, true, 8)
+	case reflect.TypeOf(tls.Certificate{}): // certificate chain and private key
+		cert, err := parseKeyPair(s)
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(vals))
+		v.Set(reflect.ValueOf(cert))
 
-	case reflect.TypeOf([]int16{}):
-		vals, err := parseIntegers[int16](s, sep, true, 16)
+	case reflect.TypeOf(&x509.CertPool{}): // CA pool
+		pool, err := parseCertPool(s)
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(vals))
+		v.Set(reflect.ValueOf(pool))
 
-	case reflect.TypeOf([]int32{}):
-		vals, err := parseIntegers[int32](s, sep, true, 32)
+	case reflect.TypeOf([]*x509.Certificate{}): // certificates
+		certs, err := parseCertificates(s)
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(vals))
+		v.Set(reflect.ValueOf(certs))
 
-	case reflect.TypeOf([]int64{}):
-		vals, err := parseIntegers[int64](s, sep, true, 64)
+	case reflect.TypeOf(x509.Certificate{}): // first certificate
+		certs, err := parseCertificates(s)
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(vals))
+		v.Set(reflect.ValueOf(certs[0]).Elem())
 
-	case reflect.TypeOf([]uint{}):
-		vals, err := parseIntegers[uint](s, sep, false, bits.UintSize)
+	case reflect.TypeOf(net.IPNet{}): // IP network
+		_, ipNet, err := net.ParseCIDR(s)
 		if err != nil {
 			return err
 		}
-		v.Set(reflect.ValueOf(vals))
+		v.Set(reflect.ValueOf(*ipNet))
 
 	case reflect.TypeOf([]uint8{}):
 		if b.Uint8Lists {
-			vals, err := parseIntegers[uint8](s, sep, false, 8)
-			if err != nil {
-				return err
-			}
-			v.Set(reflect.ValueOf(vals))
-		} else {
-			// by default we assume []uint8 to actually be []byte
-			v.Set(reflect.ValueOf([]uint8(s)))
+			return b.setList(f, v, s)
 		}
-
-	case reflect.TypeOf([]uint16{}):
-		vals, err := parseIntegers[uint16](s, sep, false, 16)
-		if err != nil {
-			return err
-		}
-		v.Set(reflect.ValueOf(vals))
-
-	case reflect.TypeOf([]uint32{}):
-		vals, err := parseIntegers[uint32](s, sep, false, 32)
-		if err != nil {
-			return err
-		}
-		v.Set(reflect.ValueOf(vals))
-
-	case reflect.TypeOf([]uint64{}):
-		vals, err := parseIntegers[uint64](s, sep, false, 64)
-		if err != nil {
-			return err
-		}
-		v.Set(reflect.ValueOf(vals))
+		// by default we assume []uint8 to actually be []byte
+		v.Set(reflect.ValueOf([]uint8(s)))
 
 	case reflect.TypeOf([]float32{}):
 		vals, err := parseFloats[float32](s, sep, 32)
@@ -592,6 +1139,20 @@ func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string)
 
 	default:
 
+		// Pointers are set to a new value parsed the same way as the element type
+		if v.Kind() == reflect.Pointer {
+			p := reflect.New(v.Type().Elem())
+			err := b.setFieldValue(f, p.Elem(), s)
+			if _, unsupported := err.(*unsupportedTypeError); unsupported {
+				return &unsupportedTypeError{v.Type()}
+			}
+			if err != nil {
+				return err
+			}
+			v.Set(p)
+			return nil
+		}
+
 		if v.CanInterface() {
 			vi := v.Interface()
 			textUnmarshaler, ok := vi.(encoding.TextUnmarshaler)
@@ -607,34 +1168,18 @@ func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string)
 		switch v.Kind() {
 
 		case reflect.Bool:
-			switch strings.ToUpper(s) {
-			case "TRUE":
-				v.SetBool(true)
-			case "FALSE":
-				v.SetBool(false)
-			default:
-				return fmt.Errorf("string %q is not a valid boolean value", s)
-			}
-
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			i, err := strconv.ParseInt(s, 10, 64)
+			bv, err := b.parseBool(s)
 			if err != nil {
 				return err
 			}
-			if v.OverflowInt(i) {
-				return errors.New("overflow error")
-			}
-			v.SetInt(i)
+			v.SetBool(bv)
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			u, err := strconv.ParseUint(s, 10, 64)
-			if err != nil {
-				return err
-			}
-			if v.OverflowUint(u) {
-				return errors.New("overflow error")
-			}
-			v.SetUint(u)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return b.setInteger(f, v, s)
+
+		case reflect.Slice:
+			return b.setList(f, v, s)
 
 		case reflect.Float32, reflect.Float64:
 			f, err := strconv.ParseFloat(s, 64)
@@ -646,143 +1191,16 @@ func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string)
 			}
 			v.SetFloat(f)
 
-		case reflect.Pointer:
-			switch v.Type().String() {
-			case "*int":
-				i64, err := strconv.ParseInt(s, 10, 64)
-				if err != nil {
-					return err
-				}
-				i := int(i64)
-				v.Set(reflect.ValueOf(&i))
-
-			case "*int8":
-				i64, err := strconv.ParseInt(s, 10, 8)
-				if err != nil {
-					return err
-				}
-				i8 := int8(i64)
-				v.Set(reflect.ValueOf(&i8))
-
-			case "*int16":
-				i64, err := strconv.ParseInt(s, 10, 16)
-				if err != nil {
-					return err
-				}
-				i16 := int16(i64)
-				v.Set(reflect.ValueOf(&i16))
-
-			case "*int32":
-				i64, err := strconv.ParseInt(s, 10, 32)
-				if err != nil {
-					return err
-				}
-				i32 := int32(i64)
-				v.Set(reflect.ValueOf(&i32))
-
-			case "*int64":
-				i64, err := strconv.ParseInt(s, 10, 64)
-				if err != nil {
-					return err
-				}
-				v.Set(reflect.ValueOf(&i64))
-
-			case "*uint":
-				ui64, err := strconv.ParseUint(s, 10, 64)
-				if err != nil {
-					return err
-				}
-				ui := uint(ui64)
-				v.Set(reflect.ValueOf(&ui))
-
-			case "*uint8":
-				ui64, err := strconv.ParseUint(s, 10, 8)
-				if err != nil {
-					return err
-				}
-				ui8 := uint8(ui64)
-				v.Set(reflect.ValueOf(&ui8))
-
-			case "*uint16":
-				ui64, err := strconv.ParseUint(s, 10, 16)
-				if err != nil {
-					return err
-				}
-				ui16 := uint16(ui64)
-				v.Set(reflect.ValueOf(&ui16))
-
-			case "*uint32":
-				ui64, err := strconv.ParseUint(s, 10, 32)
-				if err != nil {
-					return err
-				}
-				ui32 := uint32(ui64)
-				v.Set(reflect.ValueOf(&ui32))
-
-			case "*uint64":
-				ui64, err := strconv.ParseUint(s, 10, 64)
-				if err != nil {
-					return err
-				}
-				v.Set(reflect.ValueOf(&ui64))
-
-			case "*float32":
-				f64, err := strconv.ParseFloat(s, 32)
-				if err != nil {
-					return err
-				}
-				f32 := float32(f64)
-				v.Set(reflect.ValueOf(&f32))
-
-			case "*float64":
-				f64, err := strconv.ParseFloat(s, 64)
-				if err != nil {
-					return err
-				}
-				v.Set(reflect.ValueOf(&f64))
-
-			case "*time.Duration":
-				i, err := strconv.ParseInt(s, 10, 64)
-				if err != nil {
-					d, err := time.ParseDuration(s)
-					if err != nil {
-						return err
-					}
-					i = int64(d)
-				}
-				d := time.Duration(i)
-				v.Set(reflect.ValueOf(&d))
-
-			case "*url.URL":
-				u, err := url.Parse(s)
-				if err != nil {
+		case reflect.String:
+			if f.hostPort {
+				if _, _, err := net.SplitHostPort(s); err != nil {
 					return err
 				}
-				v.Set(reflect.ValueOf(u))
-
-			case "*string":
-				str := s
-				v.Set(reflect.ValueOf(&str))
-
-			case "*bool":
-				var b bool
-				switch strings.ToUpper(s) {
-				case "TRUE":
-					b = true
-				case "FALSE":
-					b = false
-				default:
-					return fmt.Errorf("string %q is not a valid boolean value", s)
-				}
-				v.Set(reflect.ValueOf(&b))
 			}
-
-		case reflect.String:
 			v.SetString(s)
 
 		default:
-			return fmt.Errorf("unsupported type/kind \"%s/%s\"",
-				v.Type().String(), v.Kind().String())
+			return &unsupportedTypeError{v.Type()}
 		}
 	}
 	return nil
@@ -791,27 +1209,16 @@ func (b *Builder[T]) setFieldValue(fieldName string, v reflect.Value, s string)
 // checkRequired looks at each field and ensures that each field with a "required" tag was
 // previously set from an env var.  An error is returned if any required fields were not set.
 func (b *Builder[T]) checkRequired() error {
-	b.printDebugFunctionStart()
-	defer b.printDebugFunctionFinish()
-	typ := reflect.TypeOf(b.cfg).Elem()
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
 	missingRequired := []string{}
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		fieldName := field.Name
-		tagValue, ok := field.Tag.Lookup(b.getTagKey())
-		if !ok {
+	for _, f := range b.plan(b.cfgType()).fields {
+		if f.name == "-" {
 			continue
 		}
-
-		envVarName := getTagEnvVarName(tagValue)
-		_, required := getTagAttribute(tagValue, tagAttrRequired)
-
-		if envVarName == "-" {
-			continue
-		}
-		if required && !b.setProps[fieldName] {
-			missingRequired = append(missingRequired, fieldName)
+		if _, set := b.setProps[f.field.Name]; f.required && !set {
+			missingRequired = append(missingRequired, f.field.Name)
 		}
 	}
 
@@ -825,6 +1232,23 @@ func (b *Builder[T]) checkRequired() error {
 	}
 }
 
+// warn passes the warning to OnWarning.  If OnWarning is not set then the warning is logged to
+// the Logger (or the standard logger if there is no Logger).
+func (b *Builder[T]) warn(err error) {
+	switch {
+	case b.OnWarning != nil:
+		b.OnWarning(err)
+	case b.Logger != nil:
+		ctx := b.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		b.Logger.WarnContext(ctx, "cfgbuild warning", "error", err)
+	default:
+		log.Printf("cfgbuild warning: %v", err)
+	}
+}
+
 // getTagKey returns the user-specified tag name or defaults to "envvar" if none is specified.
 func (b *Builder[T]) getTagKey() string {
 	if b.TagKey == "" {
@@ -833,26 +1257,60 @@ func (b *Builder[T]) getTagKey() string {
 	return b.TagKey
 }
 
-func (b *Builder[T]) printDebugFunctionStart() {
+// debugLogger is used when the debug flag is set but no Logger is provided.
+var debugLogger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+// logger returns the Logger for the Builder (or nil if logging is disabled).
+func (b *Builder[T]) logger() *slog.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
 	if b.debug {
-		pc, _, line, _ := runtime.Caller(1)
-		fmt.Printf("%sRunning function %s [line %d]\n", b.indent, funcName(pc), line)
-		b.indent += "> "
+		return debugLogger
 	}
+	return nil
 }
 
-func (b *Builder[T]) printDebugFunctionFinish() {
-	if b.debug {
+// logDebug logs a debug level event if there is a Logger.
+func (b *Builder[T]) logDebug(msg string, args ...any) {
+	l := b.logger()
+	if l == nil {
+		return
+	}
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.DebugContext(ctx, msg, args...)
+}
+
+// logFieldSet logs that a field value was set from an env var or default value.  The value is
+// redacted if the field has the "secret" attribute.
+func (b *Builder[T]) logFieldSet(f *fieldPlan, key, val string, isDefault bool) {
+	if b.logger() == nil {
+		return
+	}
+	if f.secret || mayHoldPrivateKey(f, val) {
+		val = RedactedValue
+	}
+	if isDefault {
+		b.logDebug("applied default", "field", f.field.Name, "value", val)
+		return
+	}
+	b.logDebug("set field", "field", f.field.Name, "key", key, "value", val)
+}
+
+func (b *Builder[T]) logFunctionStart() {
+	if b.logger() != nil {
 		pc, _, line, _ := runtime.Caller(1)
-		b.indent = b.indent[2:]
-		fmt.Printf("%sFinished running function %s [line %d]\n", b.indent, funcName(pc), line)
+		b.logDebug("running function", "function", funcName(pc), "line", line)
 	}
 }
 
-func (b *Builder[T]) printDebugf(msg string, a ...any) {
-	if b.debug {
-		_, _, line, _ := runtime.Caller(1)
-		fmt.Printf("%s%s [line %d]\n", b.indent, fmt.Sprintf(msg, a...), line)
+func (b *Builder[T]) logFunctionFinish() {
+	if b.logger() != nil {
+		pc, _, line, _ := runtime.Caller(1)
+		b.logDebug("finished running function", "function", funcName(pc), "line", line)
 	}
 }
 
@@ -874,29 +1332,116 @@ func split(s, sep string) []string {
 	return out
 }
 
-type integers interface {
-	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64
+// isIntegerKind returns true if the kind is a signed or unsigned integer.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
 }
 
-func parseIntegers[T integers](s, sep string, signed bool, bitsize int) ([]T, error) {
-	vals := split(s, sep)
-	integers := []T{}
-	for _, v := range vals {
-		if signed {
-			i64, err := strconv.ParseInt(v, 10, bitsize)
-			if err != nil {
-				return integers, err
-			}
-			integers = append(integers, T(i64))
-		} else {
-			u64, err := strconv.ParseUint(v, 10, bitsize)
-			if err != nil {
-				return integers, err
-			}
-			integers = append(integers, T(u64))
+// integerBase returns the base used to parse the integers of the field with type typ (see
+// strconv.ParseInt).  The "base" tag attribute takes precedence, os.FileMode values are octal, and
+// otherwise integers are decimal unless the Builder BasePrefixes is set.
+func (b *Builder[T]) integerBase(f *fieldPlan, typ reflect.Type) int {
+	switch {
+	case f.hasBase:
+		return f.base
+	case typ == reflect.TypeOf(os.FileMode(0)):
+		return 8
+	case b.BasePrefixes:
+		return 0
+	}
+	return 10
+}
+
+// setInteger parses s and sets the integer v.  All integer fields (including pointer and slice
+// items) are parsed by setInteger.
+func (b *Builder[T]) setInteger(f *fieldPlan, v reflect.Value, s string) error {
+	base := b.integerBase(f, v.Type())
+	s = trimBasePrefix(s, base)
+
+	if v.CanInt() {
+		i, err := strconv.ParseInt(s, base, 64)
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(i) {
+			return errors.New("overflow error")
+		}
+		v.SetInt(i)
+		return nil
+	}
+
+	u, err := strconv.ParseUint(s, base, 64)
+	if err != nil {
+		return err
+	}
+	if v.OverflowUint(u) {
+		return errors.New("overflow error")
+	}
+	v.SetUint(u)
+	return nil
+}
+
+// basePrefixes are the prefixes (in lower case) of the bases which have a prefix with base 0.
+var basePrefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// trimBasePrefix removes the prefix for the base (such as 0x for base 16) from the integer s so
+// that values may use the same prefix as with base 0.  A sign is kept.
+func trimBasePrefix(s string, base int) string {
+	prefix, ok := basePrefixes[base]
+	if !ok {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		s = s[len(prefix):]
+	}
+	return sign + s
+}
+
+// setList splits s into list items and sets the slice v with each item parsed the same way as
+// the element type.
+func (b *Builder[T]) setList(f *fieldPlan, v reflect.Value, s string) error {
+	items := split(s, b.ListSeparator)
+	vals := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		err := b.setFieldValue(f, vals.Index(i), item)
+		if _, unsupported := err.(*unsupportedTypeError); unsupported {
+			return &unsupportedTypeError{v.Type()}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	v.Set(vals)
+	return nil
+}
+
+// parseBool parses s using the Builder BoolSyntax.
+func (b *Builder[T]) parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	if b.BoolSyntax == BoolLenient {
+		switch strings.ToLower(s) {
+		case "1", "t", "yes", "on":
+			return true, nil
+		case "0", "f", "no", "off":
+			return false, nil
 		}
 	}
-	return integers, nil
+	return false, fmt.Errorf("string %q is not a valid boolean value", s)
 }
 
 type floats interface {
@@ -918,23 +1463,27 @@ func parseFloats[T floats](s, sep string, bitsize int) ([]T, error) {
 	return floats, nil
 }
 
-func getTagEnvVarName(tagVal string) string {
-	return strings.Split(tagVal, ",")[0]
-}
-
-// getTagAttribute looks at the tag value and returns the attribute value for the specified
-// attribute name and a bool indicator as to whether or not the attribute exists in the tag value.
-func getTagAttribute(tagVal string, attributeName tagAttr) (string, bool) {
-	prefix := string(attributeName) + "="
-	for _, a := range strings.Split(tagVal, ",") {
-		if a == string(attributeName) {
-			return "", true
-		}
-		if strings.HasPrefix(a, prefix) {
-			return strings.TrimPrefix(a, prefix), true
+// checkOneOf returns an error if there are allowed values (from the "oneof" attribute) and the
+// provided value is not one of them.
+func checkOneOf(allowed []string, val string) error {
+	if allowed == nil {
+		return nil
+	}
+	for _, a := range allowed {
+		if val == a {
+			return nil
 		}
 	}
-	return "", false
+	return fmt.Errorf("value %q is not one of %s", val, strings.Join(allowed, ", "))
+}
+
+// An unsupportedTypeError is returned when a value can't be parsed for a field type.
+type unsupportedTypeError struct {
+	typ reflect.Type
+}
+
+func (e *unsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type/kind \"%s/%s\"", e.typ.String(), e.typ.Kind().String())
 }
 
 type TagSyntaxError struct {
@@ -960,38 +1509,55 @@ func isPublicField(f reflect.StructField) bool {
 type tagAttr string
 
 const (
+	tagAttrAllowEmpty    tagAttr = "allowEmpty"
+	tagAttrBase          tagAttr = "base"
 	tagAttrDefault       tagAttr = "default"
+	tagAttrDescription   tagAttr = "description"
+	tagAttrFile          tagAttr = "file"
+	tagAttrHostPort      tagAttr = "hostport"
+	tagAttrKey           tagAttr = "key"
+	tagAttrLayout        tagAttr = "layout"
+	tagAttrNotEmpty      tagAttr = "notempty"
+	tagAttrOneOf         tagAttr = "oneof"
+	tagAttrParser        tagAttr = "parser"
 	tagAttrPrefix        tagAttr = "prefix"
 	tagAttrRequired      tagAttr = "required"
+	tagAttrSchemes       tagAttr = "schemes"
+	tagAttrSecret        tagAttr = "secret"
+	tagAttrStatic        tagAttr = "static"
+	tagAttrUnit          tagAttr = "unit"
 	tagAttrUnmarshalJSON tagAttr = "unmarshalJSON"
+	tagAttrUnsetIfEmpty  tagAttr = "unsetIfEmpty"
 )
 
 var allTagAttr = []tagAttr{
+	tagAttrAllowEmpty,
+	tagAttrBase,
 	tagAttrDefault,
+	tagAttrDescription,
+	tagAttrFile,
+	tagAttrHostPort,
+	tagAttrKey,
+	tagAttrLayout,
+	tagAttrNotEmpty,
+	tagAttrOneOf,
+	tagAttrParser,
 	tagAttrPrefix,
 	tagAttrRequired,
+	tagAttrSchemes,
+	tagAttrSecret,
+	tagAttrStatic,
+	tagAttrUnit,
 	tagAttrUnmarshalJSON,
+	tagAttrUnsetIfEmpty,
 }
 
 func (a tagAttr) hasValue() bool {
 	switch a {
-	case tagAttrDefault, tagAttrPrefix:
+	case tagAttrBase, tagAttrDefault, tagAttrDescription, tagAttrKey, tagAttrLayout, tagAttrOneOf,
+		tagAttrParser, tagAttrPrefix, tagAttrSchemes, tagAttrUnit:
 		return true
 	default:
 		return false
 	}
 }
-
-func getTagAttributeNames(tagValue string) []string {
-	attrs := []string{}
-	first := true
-	for _, a := range strings.Split(tagValue, ",") {
-		if first {
-			first = false
-			continue
-		}
-		kv := strings.Split(a, "=")
-		attrs = append(attrs, kv[0])
-	}
-	return attrs
-}
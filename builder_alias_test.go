@@ -0,0 +1,100 @@
+package cfgbuild
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestAliasConfig struct {
+	Host string          `envvar:"DB_HOST|DB_HOSTNAME|DB_SERVER,default=localhost"`
+	Port int             `envvar:"DB_PORT,required"`
+	Mail TestAliasNested `envvar:">,prefix=MAIL_"`
+}
+
+type TestAliasNested struct {
+	Host string `envvar:"HOST|HOSTNAME"`
+}
+
+func TestAliases(t *testing.T) {
+	tsts := []struct {
+		src          MapSource
+		host         string
+		mailHost     string
+		warnings     []string
+		setPropsHost string
+	}{
+		{MapSource{"DB_PORT": "1"}, "localhost", "", nil, ""},
+		{MapSource{"DB_PORT": "1", "DB_HOST": "new"}, "new", "", nil, "DB_HOST"},
+		{MapSource{"DB_PORT": "1", "DB_HOSTNAME": "old"}, "old", "", []string{
+			`variable "DB_HOSTNAME" is deprecated (use "DB_HOST" instead)`}, "DB_HOSTNAME"},
+		{MapSource{"DB_PORT": "1", "DB_SERVER": "older", "DB_HOSTNAME": "older"}, "older", "", []string{
+			`variable "DB_HOSTNAME" is deprecated (use "DB_HOST" instead)`,
+			`variable "DB_SERVER" is deprecated (use "DB_HOST" instead)`}, "DB_HOSTNAME"},
+		{MapSource{"DB_PORT": "1", "MAIL_HOSTNAME": "mail"}, "localhost", "mail", []string{
+			`variable "MAIL_HOSTNAME" is deprecated (use "MAIL_HOST" instead)`}, ""},
+	}
+
+	for i, tst := range tsts {
+		warnings := []string{}
+		b := Builder[*TestAliasConfig]{
+			Sources:   []Source{tst.src},
+			OnWarning: func(err error) { warnings = append(warnings, err.Error()) },
+		}
+		cfg, err := b.Build()
+		assert.NoError(t, err, i)
+		assert.Equal(t, tst.host, cfg.Host, i)
+		assert.Equal(t, tst.mailHost, cfg.Mail.Host, i)
+		assert.ElementsMatch(t, tst.warnings, warnings, i)
+		assert.Equal(t, tst.setPropsHost, b.setProps["Host"], i)
+	}
+}
+
+func TestAliasConflict(t *testing.T) {
+	b := Builder[*TestAliasConfig]{
+		Sources:   []Source{MapSource{"DB_PORT": "1", "DB_HOST": "new", "DB_HOSTNAME": "old"}},
+		OnWarning: func(err error) {},
+	}
+	_, err := b.Build()
+	assert.Error(t, err)
+	assert.Equal(t, `error reading "DB_HOST" (conflicting values set for "DB_HOST" and "DB_HOSTNAME")`,
+		err.Error())
+}
+
+func TestAliasStrict(t *testing.T) {
+	b := Builder[*TestAliasConfig]{
+		Sources:   []Source{MapSource{"DB_PORT": "1", "MAIL_HOSTNAME": "mail"}},
+		Strict:    StrictError,
+		OnWarning: func(err error) {},
+	}
+	_, err := b.Build()
+	assert.NoError(t, err)
+}
+
+func TestAliasEnvExample(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteEnvExample[*TestAliasNested](buf))
+	assert.Equal(t, "# Host (string)\n# Deprecated names: HOSTNAME\nHOST=\n", buf.String())
+
+	schema, err := JSONSchema[*TestAliasNested]()
+	assert.NoError(t, err)
+	assert.True(t, schema.Properties["HOSTNAME"].Deprecated)
+	assert.Equal(t, "Deprecated: use HOST instead", schema.Properties["HOSTNAME"].Description)
+	assert.False(t, schema.Properties["HOST"].Deprecated)
+}
+
+func TestAliasTagErrors(t *testing.T) {
+	for _, cfg := range []interface{}{
+		&struct {
+			MyInt int `envvar:"MY_INT||MY_OTHER_INT"`
+		}{},
+		&struct {
+			MyInt int `envvar:"-|MY_INT"`
+		}{},
+	} {
+		err := InitConfig(cfg)
+		assert.Error(t, err)
+		assert.Equal(t, "tag contains an invalid env var name alias", err.Error())
+	}
+}
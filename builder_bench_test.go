@@ -0,0 +1,75 @@
+package cfgbuild
+
+import (
+	"testing"
+	"time"
+)
+
+type BenchConfig struct {
+	Host     string            `envvar:"HOST,default=localhost"`
+	Port     int               `envvar:"PORT,default=8080,static"`
+	Debug    bool              `envvar:"DEBUG,default=false"`
+	Timeout  time.Duration     `envvar:"TIMEOUT,default=30s"`
+	Level    string            `envvar:"LEVEL,default=info,oneof=debug|info|warn|error"`
+	Password string            `envvar:"PASSWORD,required,secret"`
+	Tags     []string          `envvar:"TAGS"`
+	Labels   map[string]string `envvar:"LABELS"`
+	Ratio    float64           `envvar:"RATIO,default=0.5"`
+	Ignored  string            `envvar:"-"`
+	DB       BenchDBConfig     `envvar:">,prefix=DB_"`
+}
+
+type BenchDBConfig struct {
+	Host     string `envvar:"HOST|HOSTNAME,default=localhost"`
+	Port     int    `envvar:"PORT,default=5432"`
+	User     string `envvar:"USER,required"`
+	PoolSize int    `envvar:"POOL_SIZE,default=10,unsetIfEmpty"`
+}
+
+func benchmarkBuild(b *testing.B, src MapSource) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bldr := Builder[*BenchConfig]{Prefix: "APP_", Sources: []Source{src}}
+		if _, err := bldr.Build(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildDefaults(b *testing.B) {
+	benchmarkBuild(b, MapSource{
+		"APP_PASSWORD": "hunter2",
+		"APP_DB_USER":  "admin",
+	})
+}
+
+func BenchmarkBuildEnvVars(b *testing.B) {
+	benchmarkBuild(b, MapSource{
+		"APP_HOST":         "example.com",
+		"APP_PORT":         "9090",
+		"APP_DEBUG":        "true",
+		"APP_TIMEOUT":      "1m",
+		"APP_LEVEL":        "warn",
+		"APP_PASSWORD":     "hunter2",
+		"APP_TAGS":         "a,b,c",
+		"APP_LABELS":       "k1:v1,k2:v2",
+		"APP_RATIO":        "0.75",
+		"APP_DB_HOST":      "db.example.com",
+		"APP_DB_PORT":      "6543",
+		"APP_DB_USER":      "admin",
+		"APP_DB_POOL_SIZE": "20",
+	})
+}
+
+func BenchmarkBuildParallel(b *testing.B) {
+	src := MapSource{"APP_PASSWORD": "hunter2", "APP_DB_USER": "admin"}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			bldr := Builder[*BenchConfig]{Prefix: "APP_", Sources: []Source{src}}
+			if _, err := bldr.Build(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
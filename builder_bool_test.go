@@ -0,0 +1,61 @@
+package cfgbuild
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestBoolConfig struct {
+	Enabled bool   `envvar:"BOOL_ENABLED"`
+	Debug   *bool  `envvar:"BOOL_DEBUG"`
+	Flags   []bool `envvar:"BOOL_FLAGS"`
+}
+
+func TestBoolSyntax(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("BOOL_ENABLED", "True")
+	os.Setenv("BOOL_DEBUG", "FALSE")
+	os.Setenv("BOOL_FLAGS", "true, false,TRUE")
+
+	cfg, err := NewConfig[*TestBoolConfig]()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Enabled)
+	assert.False(t, *cfg.Debug)
+	assert.Equal(t, []bool{true, false, true}, cfg.Flags)
+
+	// the strict default only accepts true and false
+	for _, envVar := range []string{"BOOL_ENABLED", "BOOL_DEBUG", "BOOL_FLAGS"} {
+		os.Setenv(envVar, "yes")
+		_, err = NewConfig[*TestBoolConfig]()
+		assert.EqualError(t, err, `error reading "`+envVar+`" (string "yes" is not a valid boolean value)`)
+		os.Setenv(envVar, "true")
+	}
+
+	b := Builder[*TestBoolConfig]{BoolSyntax: BoolLenient}
+	tsts := []struct {
+		val      string
+		expected bool
+	}{
+		{"1", true}, {"t", true}, {"T", true}, {"TRUE", true}, {"yes", true}, {"Yes", true},
+		{"on", true}, {"ON", true},
+		{"0", false}, {"f", false}, {"F", false}, {"false", false}, {"no", false}, {"NO", false},
+		{"off", false}, {"Off", false},
+	}
+	for _, tst := range tsts {
+		os.Setenv("BOOL_ENABLED", tst.val)
+		os.Setenv("BOOL_DEBUG", tst.val)
+		os.Setenv("BOOL_FLAGS", tst.val+","+tst.val)
+		cfg, err := b.Build()
+		assert.NoError(t, err, tst.val)
+		assert.Equal(t, tst.expected, cfg.Enabled, tst.val)
+		assert.Equal(t, tst.expected, *cfg.Debug, tst.val)
+		assert.Equal(t, []bool{tst.expected, tst.expected}, cfg.Flags, tst.val)
+	}
+
+	os.Setenv("BOOL_FLAGS", "on,maybe")
+	_, err = b.Build()
+	assert.EqualError(t, err, `error reading "BOOL_FLAGS" (string "maybe" is not a valid boolean value)`)
+}
@@ -0,0 +1,118 @@
+package cfgbuild
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestByteSizeConfig struct {
+	Buffer   ByteSize   `envvar:"BUFFER,default=512KiB"`
+	Upload   *ByteSize  `envvar:"UPLOAD"`
+	Tiers    []ByteSize `envvar:"TIERS"`
+	MaxBody  int        `envvar:"MAX_BODY,unit=bytes,default=10MB"`
+	MaxCache *uint32    `envvar:"MAX_CACHE,unit=bytes"`
+	Small    int8       `envvar:"SMALL,unit=bytes"`
+}
+
+func TestParseByteSize(t *testing.T) {
+	tsts := []struct {
+		s        string
+		expected ByteSize
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"512KiB", 512 * KiB},
+		{"10MB", 10 * MB},
+		{"10mb", 10 * MB},
+		{"1.5G", 1500 * MB},
+		{"1.5GiB", 1536 * MiB},
+		{" 2 TiB ", 2 * TiB},
+		{"3k", 3 * KB},
+		{"7B", 7},
+		{"16EiB", 0},
+	}
+
+	for _, tst := range tsts {
+		size, err := ParseByteSize(tst.s)
+		if tst.s == "16EiB" {
+			assert.EqualError(t, err, "overflow error")
+			continue
+		}
+		assert.NoError(t, err, tst.s)
+		assert.Equal(t, tst.expected, size, tst.s)
+	}
+
+	for _, s := range []string{"", "MB", "ten", "-1", "5XB"} {
+		_, err := ParseByteSize(s)
+		assert.EqualError(t, err, `invalid byte size "`+s+`"`)
+	}
+
+	_, err := ParseByteSize("1.0001kB")
+	assert.EqualError(t, err, `byte size "1.0001kB" is not a whole number of bytes`)
+}
+
+func TestByteSizeString(t *testing.T) {
+	assert.Equal(t, "0B", ByteSize(0).String())
+	assert.Equal(t, "1500B", ByteSize(1500).String())
+	assert.Equal(t, "512KiB", (512 * KiB).String())
+	assert.Equal(t, "10MB", (10 * MB).String())
+	assert.Equal(t, "1536MiB", (1536 * MiB).String())
+	assert.Equal(t, "1000KiB", ByteSize(1024000).String())
+	assert.Equal(t, "3kB", (3 * KB).String())
+
+	// the string can be parsed back to the same size
+	for _, size := range []ByteSize{1500, 512 * KiB, 10 * MB, 3 * KB, 15 * EiB, math.MaxUint64} {
+		parsed, err := ParseByteSize(size.String())
+		assert.NoError(t, err)
+		assert.Equal(t, size, parsed)
+	}
+}
+
+func TestByteSizeFields(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("UPLOAD", "1.5MB")
+	os.Setenv("TIERS", "1KiB, 1MiB,1GiB")
+	os.Setenv("MAX_CACHE", "2GiB")
+
+	cfg, err := NewConfig[*TestByteSizeConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, 512*KiB, cfg.Buffer)
+	assert.Equal(t, 1500*KB, *cfg.Upload)
+	assert.Equal(t, []ByteSize{KiB, MiB, GiB}, cfg.Tiers)
+	assert.Equal(t, 10000000, cfg.MaxBody)
+	assert.Equal(t, uint32(2*GiB), *cfg.MaxCache)
+
+	m, err := Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "512KiB", m["BUFFER"])
+	assert.Equal(t, "1500kB", m["UPLOAD"])
+	assert.Equal(t, "1KiB,1MiB,1GiB", m["TIERS"])
+
+	os.Setenv("MAX_CACHE", "4GiB")
+	_, err = NewConfig[*TestByteSizeConfig]()
+	assert.EqualError(t, err, `error reading "MAX_CACHE" (overflow error)`)
+	os.Unsetenv("MAX_CACHE")
+
+	os.Setenv("SMALL", "1KB")
+	_, err = NewConfig[*TestByteSizeConfig]()
+	assert.EqualError(t, err, `error reading "SMALL" (overflow error)`)
+	os.Unsetenv("SMALL")
+
+	os.Setenv("UPLOAD", "lots")
+	_, err = NewConfig[*TestByteSizeConfig]()
+	assert.EqualError(t, err, `error reading "UPLOAD" (invalid byte size "lots")`)
+
+	err = InitConfig(&struct {
+		Size string `envvar:"SIZE,unit=bytes"`
+	}{})
+	assert.EqualError(t, err, `the "unit" attribute is only allowed on integer fields`)
+
+	err = InitConfig(&struct {
+		Size int `envvar:"SIZE,unit=bits"`
+	}{})
+	assert.EqualError(t, err, `the "unit" attribute value "bits" is not supported (use "bytes")`)
+}
@@ -0,0 +1,77 @@
+package cfgbuild
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestContextConfig struct {
+	MyInt          int              `envvar:"MY_INT"`
+	MyString       string           `envvar:"MY_STRING,default=ahoy"`
+	MyChild        TestNestedConfig `envvar:">,prefix=CHILD_"`
+	initCalled     bool
+	validateCalled bool
+}
+
+func (cfg *TestContextConfig) CfgBuildInitContext(ctx context.Context) error {
+	cfg.initCalled = ctx.Value(testContextKey{}) == "yes"
+	return nil
+}
+
+func (cfg *TestContextConfig) CfgBuildValidateContext(ctx context.Context) error {
+	cfg.validateCalled = ctx.Value(testContextKey{}) == "yes"
+	return nil
+}
+
+type testContextKey struct{}
+
+// blockingSource simulates a slow source which blocks until the context is done.
+type blockingSource struct{}
+
+func (blockingSource) Lookup(ctx context.Context, name string) (string, bool, error) {
+	<-ctx.Done()
+	return "", false, ctx.Err()
+}
+
+func TestBuildContextSources(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testContextKey{}, "yes")
+
+	b := Builder[*TestContextConfig]{Sources: []Source{
+		MapSource{"MY_INT": "42"},
+		MapSource{"MY_INT": "17", "CHILD_MY_VAL": "child"},
+	}}
+
+	cfg, err := b.BuildContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, cfg.MyInt)
+	assert.Equal(t, "ahoy", cfg.MyString)
+	assert.Equal(t, "child", cfg.MyChild.MyVal)
+	assert.True(t, cfg.initCalled)
+	assert.True(t, cfg.validateCalled)
+}
+
+func TestBuildContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := Builder[*TestContextConfig]{Sources: []Source{MapSource{"MY_INT": "42"}}}
+	_, err := b.BuildContext(ctx)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "build stopped (context canceled)", err.Error())
+}
+
+func TestBuildContextTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	b := Builder[*TestContextConfig]{Sources: []Source{blockingSource{}}}
+	_, err := b.BuildContext(ctx)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, `error reading "MY_INT" (context deadline exceeded)`, err.Error())
+}
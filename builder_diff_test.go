@@ -0,0 +1,105 @@
+package cfgbuild
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestDiffConfig struct {
+	MyInt      int               `envvar:"MY_INT"`
+	MyPassword string            `envvar:"MY_PASSWORD,secret"`
+	MyInts     []int             `envvar:"MY_INTS"`
+	MyMap      map[string]string `envvar:"MY_MAP"`
+	MyTokens   []string          `envvar:"MY_TOKENS,secret"`
+	MyIP       net.IP            `envvar:"MY_IP"`
+	MyBytes    []byte            `envvar:"MY_BYTES"`
+	MyChild    TestNestedConfig  `envvar:">,prefix=CHILD_"`
+	MyPtrChild *TestNestedConfig `envvar:">,prefix=PTR_"`
+	NotTagged  int
+}
+
+func TestDiff(t *testing.T) {
+	oldCfg := &TestDiffConfig{
+		MyInt:      1,
+		MyPassword: "hunter2",
+		MyInts:     []int{1, 2, 3},
+		MyMap:      map[string]string{"a": "1", "b": "2"},
+		MyTokens:   []string{"t1"},
+		MyIP:       net.ParseIP("10.0.0.1"),
+		MyBytes:    []byte("abc"),
+		MyChild:    TestNestedConfig{MyVal: "old"},
+		NotTagged:  1,
+	}
+	newCfg := &TestDiffConfig{
+		MyInt:      2,
+		MyPassword: "correct horse",
+		MyInts:     []int{1, 5},
+		MyMap:      map[string]string{"a": "1", "b": "3", "c": "4"},
+		MyTokens:   []string{"t2"},
+		MyIP:       net.ParseIP("10.0.0.2"),
+		MyBytes:    []byte("abd"),
+		MyChild:    TestNestedConfig{MyVal: "new"},
+		MyPtrChild: &TestNestedConfig{MyVal: "ptr"},
+		NotTagged:  2,
+	}
+
+	assert.Equal(t, []FieldChange{
+		{Path: "MyInt", EnvVar: "MY_INT", Old: 1, New: 2},
+		{Path: "MyPassword", EnvVar: "MY_PASSWORD", Old: RedactedValue, New: RedactedValue},
+		{Path: "MyInts[1]", EnvVar: "MY_INTS", Old: 2, New: 5},
+		{Path: "MyInts[2]", EnvVar: "MY_INTS", Old: 3, New: nil},
+		{Path: "MyMap[b]", EnvVar: "MY_MAP", Old: "2", New: "3"},
+		{Path: "MyMap[c]", EnvVar: "MY_MAP", Old: nil, New: "4"},
+		{Path: "MyTokens", EnvVar: "MY_TOKENS", Old: RedactedValue, New: RedactedValue},
+		{Path: "MyIP", EnvVar: "MY_IP", Old: oldCfg.MyIP, New: newCfg.MyIP},
+		{Path: "MyBytes", EnvVar: "MY_BYTES", Old: []byte("abc"), New: []byte("abd")},
+		{Path: "MyChild.MyVal", EnvVar: "CHILD_MY_VAL", Old: "old", New: "new"},
+		{Path: "MyPtrChild.MyVal", EnvVar: "PTR_MY_VAL", Old: nil, New: "ptr"},
+	}, Diff(oldCfg, newCfg))
+
+	assert.Empty(t, Diff(oldCfg, oldCfg))
+}
+
+func TestDiffNilConfig(t *testing.T) {
+	changes := Diff(nil, &TestNestedConfig{MyVal: "new"})
+	assert.Equal(t, []FieldChange{
+		{Path: "MyVal", EnvVar: "MY_VAL", Old: nil, New: "new"},
+	}, changes)
+}
+
+func TestDiffEmptyCollections(t *testing.T) {
+	// nil and empty collections are considered equal
+	assert.Empty(t, Diff(&TestDiffConfig{MyInts: []int{}}, &TestDiffConfig{}))
+}
+
+func TestDiffDefaultOnlyFields(t *testing.T) {
+	// "-" fields aren't read from an env var so they aren't reported
+	type defaultOnlyConfig struct {
+		Name    string           `envvar:"-,default=app"`
+		MyChild TestNestedConfig `envvar:">,prefix=CHILD_"`
+		Hidden  TestNestedConfig `envvar:"-"`
+	}
+	changes := (&Builder[*defaultOnlyConfig]{Prefix: "APP_"}).Diff(
+		&defaultOnlyConfig{Name: "old", Hidden: TestNestedConfig{MyVal: "old"}},
+		&defaultOnlyConfig{Name: "new", MyChild: TestNestedConfig{MyVal: "new"}})
+	assert.Equal(t, []FieldChange{
+		{Path: "MyChild.MyVal", EnvVar: "APP_CHILD_MY_VAL", Old: "", New: "new"},
+	}, changes)
+}
+
+func TestDiffSecretMap(t *testing.T) {
+	type secretConfig struct {
+		Secret map[string]string `envvar:"SECRET,secret"`
+	}
+
+	// secret maps are compared as a whole so the keys aren't reported
+	changes := Diff(&secretConfig{Secret: map[string]string{"pw": "old", "user": "u"}},
+		&secretConfig{Secret: map[string]string{"pw": "new", "user": "u"}})
+	assert.Equal(t, []FieldChange{
+		{Path: "Secret", EnvVar: "SECRET", Old: RedactedValue, New: RedactedValue},
+	}, changes)
+
+	assert.Empty(t, Diff(&secretConfig{Secret: map[string]string{}}, &secretConfig{}))
+}
@@ -0,0 +1,140 @@
+package cfgbuild
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestEmptyConfig struct {
+	MyInt      int    `envvar:"MY_EMPTY_INT,default=7"`
+	MyString   string `envvar:"MY_EMPTY_STRING,required"`
+	MyNotEmpty string `envvar:"MY_EMPTY_NOT_EMPTY,notempty,default=x"`
+	MyAllowed  int    `envvar:"MY_EMPTY_ALLOWED,allowEmpty,default=3"`
+	MyUnset    string `envvar:"MY_EMPTY_UNSET,unsetIfEmpty,default=y"`
+}
+
+func setEmptyTestEnvVars() {
+	os.Setenv("MY_EMPTY_INT", "")
+	os.Setenv("MY_EMPTY_STRING", "")
+	os.Setenv("MY_EMPTY_ALLOWED", "")
+	os.Setenv("MY_EMPTY_UNSET", "")
+	os.Unsetenv("MY_EMPTY_NOT_EMPTY")
+}
+
+func TestEmptyAsValue(t *testing.T) {
+	setEmptyTestEnvVars()
+	defer os.Clearenv()
+
+	// by default an empty value is parsed like any other value
+	b := Builder[*TestEmptyConfig]{}
+	_, err := b.Build()
+	assert.Error(t, err)
+	assert.Equal(t, `error reading "MY_EMPTY_INT" (strconv.ParseInt: parsing "": invalid syntax)`,
+		err.Error())
+
+	os.Setenv("MY_EMPTY_INT", "42")
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "", cfg.MyString)
+	assert.Equal(t, "x", cfg.MyNotEmpty)
+	assert.Equal(t, 0, cfg.MyAllowed)
+	assert.Equal(t, "y", cfg.MyUnset)
+}
+
+func TestEmptyAsUnset(t *testing.T) {
+	setEmptyTestEnvVars()
+	defer os.Clearenv()
+
+	b := Builder[*TestEmptyConfig]{EmptyPolicy: EmptyAsUnset}
+	_, err := b.Build()
+	assert.Error(t, err)
+	assert.Equal(t, `missing required var "MyString"`, err.Error())
+
+	os.Setenv("MY_EMPTY_STRING", "set")
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 7, cfg.MyInt)
+	assert.Equal(t, "set", cfg.MyString)
+	assert.Equal(t, 0, cfg.MyAllowed)
+}
+
+func TestEmptyAsError(t *testing.T) {
+	setEmptyTestEnvVars()
+	defer os.Clearenv()
+
+	b := Builder[*TestEmptyConfig]{EmptyPolicy: EmptyAsError}
+	_, err := b.Build()
+	assert.Error(t, err)
+	assert.Equal(t, `error reading "MY_EMPTY_INT" (value may not be empty)`, err.Error())
+
+	os.Setenv("MY_EMPTY_INT", "1")
+	os.Setenv("MY_EMPTY_STRING", "set")
+	os.Setenv("MY_EMPTY_NOT_EMPTY", "")
+	_, err = (&Builder[*TestEmptyConfig]{}).Build()
+	assert.Error(t, err)
+	assert.Equal(t, `error reading "MY_EMPTY_NOT_EMPTY" (value may not be empty)`, err.Error())
+}
+
+func TestEmptyAsZero(t *testing.T) {
+	setEmptyTestEnvVars()
+	defer os.Clearenv()
+
+	b := Builder[*TestEmptyConfig]{EmptyPolicy: EmptyAsZero}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.MyInt)
+	assert.Equal(t, "", cfg.MyString)
+	assert.Equal(t, 0, cfg.MyAllowed)
+	assert.Equal(t, "y", cfg.MyUnset)
+}
+
+func TestEmptyPrefixFallback(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("PREFIX_MY_STRING", "")
+	os.Setenv("MY_STRING", "fallback")
+
+	b := Builder[*TestPrefixFallbackParentConfig]{PrefixFallback: true, EmptyPolicy: EmptyAsUnset}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback", cfg.MyChild.MyString)
+}
+
+func TestEmptyTagErrors(t *testing.T) {
+	err := InitConfig(&struct {
+		MyInt int `envvar:"MY_INT,notempty,allowEmpty"`
+	}{})
+	assert.Error(t, err)
+	assert.Equal(t, `the "notempty" and "allowEmpty" attributes may not be used together`, err.Error())
+
+	err = InitConfig(&struct {
+		MyInt int `envvar:"-,unsetIfEmpty"`
+	}{})
+	assert.Error(t, err)
+	assert.Equal(t, `the "unsetIfEmpty" attribute is not allowed on "-" fields`, err.Error())
+}
+
+func TestEmptyOneOf(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("LEVEL", "")
+	os.Setenv("OPTIONAL_LEVEL", "")
+
+	// an empty value is only allowed if the empty string is one of the allowed values
+	_, err := NewConfig[*struct {
+		Level string `envvar:"LEVEL,allowEmpty,oneof=info|warn"`
+	}]()
+	assert.EqualError(t, err, `error reading "LEVEL" (value "" is not one of info, warn)`)
+
+	b := Builder[*struct {
+		Level string `envvar:"LEVEL,oneof=info|warn"`
+	}]{EmptyPolicy: EmptyAsZero}
+	_, err = b.Build()
+	assert.EqualError(t, err, `error reading "LEVEL" (value "" is not one of info, warn)`)
+
+	cfg, err := NewConfig[*struct {
+		Level string `envvar:"OPTIONAL_LEVEL,allowEmpty,oneof=|info|warn"`
+	}]()
+	assert.NoError(t, err)
+	assert.Equal(t, "", cfg.Level)
+}
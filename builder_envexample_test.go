@@ -0,0 +1,108 @@
+package cfgbuild
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestEnvExampleConfig struct {
+	MyInt    int                   `envvar:"MY_INT,required,description=The answer"`
+	MyColor  string                `envvar:"MY_COLOR,default=red,oneof=red|green|blue"`
+	MyGreet  string                `envvar:"MY_GREETING,default=hello world"`
+	Ignored  int                   `envvar:"-,default=7"`
+	MySame   int                   `envvar:"MY_INT"`
+	MyChild  TestEnvExampleChild   `envvar:">,prefix=CHILD_"`
+	MyNested *TestEnvExampleNested `envvar:">,prefix=NESTED_"`
+}
+
+type TestEnvExampleChild struct {
+	MyBool bool `envvar:"MY_BOOL,default=true"`
+}
+
+type TestEnvExampleNested struct {
+	MyChild TestEnvExampleChild `envvar:">,prefix=INNER_"`
+}
+
+func TestWriteEnvExample(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteEnvExample[*TestEnvExampleConfig](buf)
+	assert.NoError(t, err)
+
+	expected := `# MyInt (int, required)
+# The answer
+MY_INT=
+
+# MyColor (string)
+# Allowed values: red, green, blue
+MY_COLOR=red
+
+# MyGreet (string)
+MY_GREETING="hello world"
+
+# MyChild.MyBool (bool)
+CHILD_MY_BOOL=true
+
+# MyNested.MyChild.MyBool (bool)
+INNER_MY_BOOL=true
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteEnvExampleBadTag(t *testing.T) {
+	type badTag struct {
+		MyInt int `envvar:"MY_INT,ninja"`
+	}
+
+	err := WriteEnvExample[*badTag](&bytes.Buffer{})
+	assert.Error(t, err)
+	assert.Equal(t, `tag value contains non-existent attribute "ninja"`, err.Error())
+}
+
+func TestOneOf(t *testing.T) {
+	os.Setenv("MY_INT", "42")
+
+	os.Setenv("MY_COLOR", "green")
+	cfg, err := NewConfig[*TestEnvExampleConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, "green", cfg.MyColor)
+
+	os.Setenv("MY_COLOR", "purple")
+	_, err = NewConfig[*TestEnvExampleConfig]()
+	assert.Error(t, err)
+	assert.Equal(t, `error reading "MY_COLOR" (value "purple" is not one of red, green, blue)`,
+		err.Error())
+
+	os.Unsetenv("MY_COLOR")
+}
+
+func TestNestedPrefixesDontAccumulate(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("MY_INT", "42")
+
+	// the prefix of a nested config replaces the prefix of the config containing it
+	os.Setenv("NESTED_INNER_MY_BOOL", "false")
+	cfg, err := NewConfig[*TestEnvExampleConfig]()
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.MyNested)
+
+	os.Setenv("INNER_MY_BOOL", "false")
+	cfg, err = NewConfig[*TestEnvExampleConfig]()
+	assert.NoError(t, err)
+	assert.False(t, cfg.MyNested.MyChild.MyBool)
+
+	// the Builder Prefix is applied to every nested config
+	os.Clearenv()
+	os.Setenv("APP_MY_INT", "42")
+	os.Setenv("APP_INNER_MY_BOOL", "false")
+	b := Builder[*TestEnvExampleConfig]{Prefix: "APP_"}
+	cfg, err = b.Build()
+	assert.NoError(t, err)
+	assert.False(t, cfg.MyNested.MyChild.MyBool)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, b.WriteEnvExample(buf))
+	assert.Contains(t, buf.String(), "\nAPP_INNER_MY_BOOL=true\n")
+}
@@ -3,6 +3,7 @@ package cfgbuild
 import (
 	"errors"
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -82,3 +83,33 @@ func TestConfigBuildNonStructType(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, `builder panic:  reflect: NumField of non-struct type int`, err.Error())
 }
+
+func TestParseError(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("APP_MY_INT", "forty-two")
+	b := Builder[*TestConfig]{Prefix: "APP_"}
+	_, err := b.Build()
+
+	var parseErr *ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "APP_MY_INT", parseErr.Name)
+	assert.Equal(t, "MyInt", parseErr.Field)
+	assert.False(t, parseErr.Default)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+	type badIntDefault struct {
+		MyInt int `envvar:"MY_INT,default=abc"`
+	}
+	_, err = NewConfig[*badIntDefault]()
+	assert.True(t, errors.As(err, &parseErr))
+	assert.True(t, parseErr.Default)
+
+	// decoder and oneof errors are also parse errors
+	os.Setenv("MODE", "fast")
+	err = InitConfig(&struct {
+		Mode string `envvar:"MODE,oneof=a|b"`
+	}{})
+	assert.True(t, errors.As(err, &parseErr))
+	assert.EqualError(t, err, `error reading "MODE" (value "fast" is not one of a, b)`)
+}
@@ -0,0 +1,160 @@
+package cfgbuild
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestIntegerConfig struct {
+	Count    int           `envvar:"INT_COUNT"`
+	Mask     uint32        `envvar:"INT_MASK"`
+	Limit    *int64        `envvar:"INT_LIMIT"`
+	Ports    []uint16      `envvar:"INT_PORTS"`
+	Color    uint32        `envvar:"INT_COLOR,base=16"`
+	Small    *int8         `envvar:"INT_SMALL"`
+	Smalls   []int8        `envvar:"INT_SMALLS"`
+	Mode     os.FileMode   `envvar:"INT_MODE,default=0644"`
+	DirMode  *os.FileMode  `envvar:"INT_DIR_MODE"`
+	Modes    []os.FileMode `envvar:"INT_MODES"`
+	HexModes []os.FileMode `envvar:"INT_HEX_MODES,base=0"`
+}
+
+func TestIntegerBases(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("INT_COLOR", "ff8800")
+	os.Setenv("INT_DIR_MODE", "0o755")
+	os.Setenv("INT_MODES", "600, 0640")
+	os.Setenv("INT_HEX_MODES", "0x1ed,0o600")
+
+	cfg, err := NewConfig[*TestIntegerConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0xff8800), cfg.Color)
+	assert.Equal(t, os.FileMode(0644), cfg.Mode)
+	assert.Equal(t, os.FileMode(0755), *cfg.DirMode)
+	assert.Equal(t, []os.FileMode{0600, 0640}, cfg.Modes)
+	assert.Equal(t, []os.FileMode{0755, 0600}, cfg.HexModes)
+
+	// integers are decimal by default
+	for _, envVar := range []string{"INT_COUNT", "INT_LIMIT", "INT_PORTS"} {
+		os.Setenv(envVar, "0x1F")
+		_, err = NewConfig[*TestIntegerConfig]()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `parsing "0x1F": invalid syntax`)
+		os.Unsetenv(envVar)
+	}
+
+	os.Setenv("INT_COUNT", "1_000_000")
+	os.Setenv("INT_MASK", "0b1010")
+	os.Setenv("INT_LIMIT", "0x1F")
+	os.Setenv("INT_PORTS", "0o17, 0x50,80")
+
+	b := Builder[*TestIntegerConfig]{BasePrefixes: true}
+	cfg, err = b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000, cfg.Count)
+	assert.Equal(t, uint32(10), cfg.Mask)
+	assert.Equal(t, int64(31), *cfg.Limit)
+	assert.Equal(t, []uint16{15, 80, 80}, cfg.Ports)
+	assert.Equal(t, uint32(0xff8800), cfg.Color)
+	assert.Equal(t, os.FileMode(0644), cfg.Mode)
+
+	// the values round trip through Marshal
+	m, err := b.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "ff8800", m["INT_COLOR"])
+	assert.Equal(t, "644", m["INT_MODE"])
+	assert.Equal(t, "600,640", m["INT_MODES"])
+
+	// scalar, pointer, and slice integers report the same errors
+	for _, envVar := range []string{"INT_SMALL", "INT_SMALLS"} {
+		os.Setenv(envVar, "300")
+		_, err = b.Build()
+		assert.EqualError(t, err, `error reading "`+envVar+`" (overflow error)`)
+		os.Unsetenv(envVar)
+	}
+
+	os.Setenv("INT_MODES", "0644,0800")
+	_, err = b.Build()
+	assert.EqualError(t, err,
+		`error reading "INT_MODES" (strconv.ParseUint: parsing "0800": invalid syntax)`)
+
+	err = InitConfig(&struct {
+		Color uint32 `envvar:"COLOR,base=hex"`
+	}{})
+	assert.EqualError(t, err, `the "base" attribute value "hex" is not a valid base (0 or 2 to 36)`)
+
+	err = InitConfig(&struct {
+		Color string `envvar:"COLOR,base=16"`
+	}{})
+	assert.EqualError(t, err, `the "base" attribute is only allowed on integer fields`)
+}
+
+func TestIntegerBasePrefixes(t *testing.T) {
+	defer os.Clearenv()
+
+	type prefixConfig struct {
+		Color  uint32   `envvar:"COLOR,base=16"`
+		Offset int      `envvar:"OFFSET,base=16"`
+		Flags  uint8    `envvar:"FLAGS,base=2"`
+		Masks  []int8   `envvar:"MASKS,base=2"`
+		Mode   uint16   `envvar:"MODE,base=8"`
+		Digits []uint64 `envvar:"DIGITS,base=36"`
+	}
+
+	// the prefix for the base is optional
+	os.Setenv("COLOR", "0xFF8800")
+	os.Setenv("OFFSET", "-0X1f")
+	os.Setenv("FLAGS", "0b1010")
+	os.Setenv("MASKS", "0B11,-0b1,+101")
+	os.Setenv("MODE", "0o755")
+	os.Setenv("DIGITS", "z,10")
+
+	cfg, err := NewConfig[*prefixConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0xff8800), cfg.Color)
+	assert.Equal(t, -31, cfg.Offset)
+	assert.Equal(t, uint8(10), cfg.Flags)
+	assert.Equal(t, []int8{3, -1, 5}, cfg.Masks)
+	assert.Equal(t, uint16(0755), cfg.Mode)
+	assert.Equal(t, []uint64{35, 36}, cfg.Digits)
+
+	// only the prefix for the base is allowed
+	os.Setenv("FLAGS", "0x1")
+	_, err = NewConfig[*prefixConfig]()
+	assert.EqualError(t, err, `error reading "FLAGS" (strconv.ParseUint: parsing "0x1": invalid syntax)`)
+	os.Setenv("FLAGS", "0b")
+	_, err = NewConfig[*prefixConfig]()
+	assert.EqualError(t, err, `error reading "FLAGS" (strconv.ParseUint: parsing "0b": invalid syntax)`)
+	os.Unsetenv("FLAGS")
+
+	// the schema patterns match the values which can be parsed
+	schema, err := JSONSchema[*prefixConfig]()
+	assert.NoError(t, err)
+
+	tsts := []struct {
+		name  string
+		valid []string
+		bad   []string
+	}{
+		{"COLOR", []string{"ff8800", "0xFF8800", "0X1"}, []string{"-1", "0x", "0b1g"}},
+		{"OFFSET", []string{"-0X1f", "+a", "10"}, []string{"0x-1", "g"}},
+		{"FLAGS", []string{"0b1010", "1"}, []string{"2", "0x1", "-1"}},
+		{"MASKS", []string{"0B11,-0b1,+101", "1"}, []string{"1,2", "0b"}},
+		{"MODE", []string{"0o755", "644"}, []string{"8", "0x7"}},
+		{"DIGITS", []string{"z,10", "Zz"}, []string{"z!", "-1"}},
+	}
+	for _, tst := range tsts {
+		re, err := regexp.Compile(schema.Properties[tst.name].Pattern)
+		assert.NoError(t, err, tst.name)
+		for _, v := range tst.valid {
+			assert.True(t, re.MatchString(v), "%s should match %q", tst.name, v)
+		}
+		for _, v := range tst.bad {
+			assert.False(t, re.MatchString(v), "%s should not match %q", tst.name, v)
+		}
+	}
+}
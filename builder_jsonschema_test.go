@@ -0,0 +1,118 @@
+package cfgbuild
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestJSONSchemaConfig struct {
+	MyInt      int                  `envvar:"MY_INT,required,description=The answer"`
+	MyUint     *uint8               `envvar:"MY_UINT"`
+	MyFloat    float64              `envvar:"MY_FLOAT,default=2.718"`
+	MyBool     bool                 `envvar:"MY_BOOL"`
+	MyDuration time.Duration        `envvar:"MY_DURATION"`
+	MyTime     time.Time            `envvar:"MY_TIME"`
+	MyURL      url.URL              `envvar:"MY_URL"`
+	MyInts     []int                `envvar:"MY_INTS"`
+	MyColor    string               `envvar:"MY_COLOR,default=red,oneof=red|green|blue"`
+	MyJSON     map[string]int       `envvar:"MY_JSON,unmarshalJSON"`
+	MyChild    TestEnvExampleChild  `envvar:">,prefix=CHILD_"`
+	MyText     TestJSONSchemaEnum   `envvar:"MY_TEXT"`
+	Ignored    int                  `envvar:"-,default=7"`
+	MyPtrChild *TestEnvExampleChild `envvar:">,prefix=PTR_"`
+}
+
+type TestJSONSchemaEnum int
+
+func (e *TestJSONSchemaEnum) UnmarshalText(buf []byte) error {
+	return nil
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := JSONSchema[*TestJSONSchemaConfig]()
+	assert.NoError(t, err)
+
+	assert.Equal(t, JSONSchemaDraft, schema.Schema)
+	assert.Equal(t, "TestJSONSchemaConfig", schema.Title)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"MY_INT"}, schema.Required)
+
+	names := []string{}
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"MY_INT", "MY_UINT", "MY_FLOAT", "MY_BOOL", "MY_DURATION",
+		"MY_TIME", "MY_URL", "MY_INTS", "MY_COLOR", "MY_JSON", "CHILD_MY_BOOL", "MY_TEXT",
+		"PTR_MY_BOOL"}, names)
+
+	assert.Equal(t, "The answer", schema.Properties["MY_INT"].Description)
+	assert.Equal(t, "2.718", *schema.Properties["MY_FLOAT"].Default)
+	assert.Nil(t, schema.Properties["MY_INT"].Default)
+	assert.Equal(t, []string{"red", "green", "blue"}, schema.Properties["MY_COLOR"].Enum)
+	assert.Equal(t, "date-time", schema.Properties["MY_TIME"].Format)
+	assert.Equal(t, "uri-reference", schema.Properties["MY_URL"].Format)
+	assert.Equal(t, "application/json", schema.Properties["MY_JSON"].ContentMediaType)
+	assert.Empty(t, schema.Properties["MY_JSON"].Pattern)
+	assert.Empty(t, schema.Properties["MY_TEXT"].Pattern)
+	assert.Empty(t, schema.Properties["MY_COLOR"].Pattern)
+
+	tsts := []struct {
+		name  string
+		valid []string
+		bad   []string
+	}{
+		{"MY_INT", []string{"42", "-42", "+7"}, []string{"forty-two", "4.2", ""}},
+		{"MY_UINT", []string{"42"}, []string{"-42", "x"}},
+		{"MY_FLOAT", []string{"2.718", "-1", ".5", "6.02e23"}, []string{"pi"}},
+		{"MY_BOOL", []string{"true", "FALSE", "tRuE"}, []string{"yes", "1"}},
+		{"MY_DURATION", []string{"3s", "1h30m", "100ms", "100000000"}, []string{"3ly"}},
+		{"MY_TIME", []string{"2022-10-10T21:01:16+00:00", "2000-03-17T13:37:00Z"}, []string{"1999"}},
+		{"MY_URL", []string{"https://www.nathanbak.com/?p=744", "/relative"}, []string{"has space"}},
+		{"MY_INTS", []string{"1", "1,2, 3"}, []string{"1,,2", "a,b"}},
+		{"CHILD_MY_BOOL", []string{"false"}, []string{"no"}},
+	}
+
+	for _, tst := range tsts {
+		re, err := regexp.Compile(schema.Properties[tst.name].Pattern)
+		assert.NoError(t, err, tst.name)
+		for _, v := range tst.valid {
+			assert.True(t, re.MatchString(v), "%s should match %q", tst.name, v)
+		}
+		for _, v := range tst.bad {
+			assert.False(t, re.MatchString(v), "%s should not match %q", tst.name, v)
+		}
+	}
+
+	buf, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), `"$schema":"https://json-schema.org/draft/2020-12/schema"`)
+	assert.Contains(t, string(buf), `"required":["MY_INT"]`)
+}
+
+func TestJSONSchemaListSeparator(t *testing.T) {
+	b := Builder[*TestJSONSchemaConfig]{ListSeparator: ";"}
+	schema, err := b.JSONSchema()
+	assert.NoError(t, err)
+
+	re := regexp.MustCompile(schema.Properties["MY_INTS"].Pattern)
+	assert.True(t, re.MatchString("1;2;3"))
+	assert.False(t, re.MatchString("1,2,3"))
+}
+
+func TestJSONSchemaDecoded(t *testing.T) {
+	// values are decoded before they are parsed and checked so only the type is described
+	schema, err := JSONSchema[*struct {
+		Env  string `envvar:"ENV,parser=lower,oneof=dev|prod,default=dev"`
+		Port int    `envvar:"PORT,parser=trim"`
+	}]()
+	assert.NoError(t, err)
+	assert.Equal(t, &SchemaProperty{Type: "string", Default: schema.Properties["ENV"].Default},
+		schema.Properties["ENV"])
+	assert.Equal(t, "dev", *schema.Properties["ENV"].Default)
+	assert.Equal(t, &SchemaProperty{Type: "string"}, schema.Properties["PORT"])
+}
@@ -0,0 +1,109 @@
+package cfgbuild
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestLoggingConfig struct {
+	Name     string `envvar:"NAME,default=app"`
+	Password string `envvar:"PASSWORD,secret"`
+	Port     int    `envvar:"PORT"`
+	hooks    []string
+}
+
+func (cfg *TestLoggingConfig) CfgBuildInit() error {
+	cfg.hooks = append(cfg.hooks, "init")
+	return nil
+}
+
+func (cfg *TestLoggingConfig) CfgBuildValidate() error {
+	cfg.hooks = append(cfg.hooks, "validate")
+	return nil
+}
+
+func readLogEvents(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	events := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		delete(event, "time")
+		events = append(events, event)
+	}
+	return events
+}
+
+func findLogEvents(events []map[string]interface{}, msg string) []map[string]interface{} {
+	found := []map[string]interface{}{}
+	for _, event := range events {
+		if event["msg"] == msg {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	b := Builder[*TestLoggingConfig]{
+		Prefix:         "APP_",
+		PrefixFallback: true,
+		Sources:        []Source{MapSource{"APP_PASSWORD": "hunter2", "PORT": "8080"}},
+		Logger:         slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", cfg.Password)
+	assert.Equal(t, 8080, cfg.Port)
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	events := readLogEvents(t, buf)
+
+	hooks := []string{}
+	for _, event := range findLogEvents(events, "invoking hook") {
+		hooks = append(hooks, event["hook"].(string))
+	}
+	assert.Equal(t, []string{"CfgBuildInit", "CfgBuildValidate"}, hooks)
+
+	lookups := findLogEvents(events, "looked up var")
+	assert.Contains(t, lookups, map[string]interface{}{"level": "DEBUG", "msg": "looked up var",
+		"key": "APP_PORT", "found": false, "fallback": false})
+	assert.Contains(t, lookups, map[string]interface{}{"level": "DEBUG", "msg": "looked up var",
+		"key": "PORT", "found": true, "fallback": true})
+
+	defaults := findLogEvents(events, "applied default")
+	if assert.Len(t, defaults, 1) {
+		assert.Equal(t, "Name", defaults[0]["field"])
+		assert.Equal(t, "app", defaults[0]["value"])
+	}
+
+	sets := map[string]map[string]interface{}{}
+	for _, event := range findLogEvents(events, "set field") {
+		sets[event["field"].(string)] = event
+	}
+	assert.Equal(t, RedactedValue, sets["Password"]["value"])
+	assert.Equal(t, "APP_PASSWORD", sets["Password"]["key"])
+	assert.Equal(t, "8080", sets["Port"]["value"])
+	assert.Equal(t, "PORT", sets["Port"]["key"])
+}
+
+func TestLoggerWarnings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	b := Builder[*TestAliasConfig]{
+		Sources: []Source{MapSource{"DB_PORT": "1", "DB_HOSTNAME": "old"}},
+		Logger:  slog.New(slog.NewJSONHandler(buf, nil)),
+	}
+	_, err := b.Build()
+	assert.NoError(t, err)
+
+	events := readLogEvents(t, buf)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "WARN", events[0]["level"])
+		assert.Equal(t, `variable "DB_HOSTNAME" is deprecated (use "DB_HOST" instead)`, events[0]["error"])
+	}
+}
@@ -0,0 +1,141 @@
+package cfgbuild
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestMarshalConfig struct {
+	MyInt      int               `envvar:"MY_INT"`
+	MyUInt8    uint8             `envvar:"MY_UINT8"`
+	MyFloat    float32           `envvar:"MY_FLOAT"`
+	MyBool     bool              `envvar:"MY_BOOL"`
+	MyString   string            `envvar:"MY_STRING"`
+	MyDuration time.Duration     `envvar:"MY_DURATION"`
+	MyTime     time.Time         `envvar:"MY_TIME"`
+	MyURL      *url.URL          `envvar:"MY_URL"`
+	MyIP       net.IP            `envvar:"MY_IP"`
+	MyBytes    []byte            `envvar:"MY_BYTES"`
+	MyInts     []int             `envvar:"MY_INTS"`
+	MyMap      map[string]string `envvar:"MY_MAP"`
+	MyJSON     TestNestedConfig  `envvar:"MY_JSON,unmarshalJSON"`
+	MyPtr      *int              `envvar:"MY_PTR"`
+	MyNilPtr   *int              `envvar:"MY_NIL_PTR"`
+	Ignored    int               `envvar:"-"`
+	MyChild    TestNestedConfig  `envvar:">,prefix=CHILD_"`
+	MyNilChild *TestNestedConfig `envvar:">,prefix=NIL_"`
+	MyPtrChild *TestMarshalChild `envvar:">,prefix=PTR_"`
+	NotTagged  string
+}
+
+type TestMarshalChild struct {
+	MyGrandChild TestNestedConfig `envvar:">,prefix=GRAND_"`
+}
+
+func newTestMarshalConfig() *TestMarshalConfig {
+	u, _ := url.Parse("https://www.nathanbak.com/?p=744")
+	ptr := 7
+	return &TestMarshalConfig{
+		MyInt:      -42,
+		MyUInt8:    8,
+		MyFloat:    2.718,
+		MyBool:     true,
+		MyString:   "Nobody expects the Spanish Inquisition!",
+		MyDuration: 90 * time.Second,
+		MyTime:     time.Date(2000, time.March, 17, 0, 13, 37, 500, time.UTC),
+		MyURL:      u,
+		MyIP:       net.ParseIP("192.168.0.42"),
+		MyBytes:    []byte("secretPassword"),
+		MyInts:     []int{1, 2, 3},
+		MyMap:      map[string]string{"b": "2", "a": "1"},
+		MyJSON:     TestNestedConfig{MyVal: "json"},
+		MyPtr:      &ptr,
+		Ignored:    9,
+		MyChild:    TestNestedConfig{MyVal: "child"},
+		MyPtrChild: &TestMarshalChild{MyGrandChild: TestNestedConfig{MyVal: "grand"}},
+		NotTagged:  "not tagged",
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	m, err := Marshal(newTestMarshalConfig())
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"MY_INT":       "-42",
+		"MY_UINT8":     "8",
+		"MY_FLOAT":     "2.718",
+		"MY_BOOL":      "true",
+		"MY_STRING":    "Nobody expects the Spanish Inquisition!",
+		"MY_DURATION":  "1m30s",
+		"MY_TIME":      "2000-03-17T00:13:37.0000005Z",
+		"MY_URL":       "https://www.nathanbak.com/?p=744",
+		"MY_IP":        "192.168.0.42",
+		"MY_BYTES":     "secretPassword",
+		"MY_INTS":      "1,2,3",
+		"MY_MAP":       "a:1,b:2",
+		"MY_JSON":      `{"MyVal":"json"}`,
+		"MY_PTR":       "7",
+		"CHILD_MY_VAL": "child",
+		"GRAND_MY_VAL": "grand",
+	}, m)
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	expected := newTestMarshalConfig()
+	expected.Ignored = 0
+	expected.NotTagged = ""
+
+	b := Builder[*TestMarshalConfig]{ListSeparator: ";", KeyValueSeparator: "="}
+	environ, err := b.MarshalEnviron(expected)
+	assert.NoError(t, err)
+	assert.Contains(t, environ, "MY_MAP=a=1;b=2")
+
+	os.Clearenv()
+	defer os.Clearenv()
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		os.Setenv(parts[0], parts[1])
+	}
+
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, cfg)
+}
+
+func TestMarshalEnviron(t *testing.T) {
+	environ, err := MarshalEnviron(&TestNestedConfig{MyVal: "my val"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MY_VAL=my val"}, environ)
+}
+
+func TestMarshalErrors(t *testing.T) {
+	_, err := Marshal((*TestNestedConfig)(nil))
+	assert.Error(t, err)
+	assert.Equal(t, "unable to marshal nil *cfgbuild.TestNestedConfig", err.Error())
+
+	_, err = Marshal(&TestMarshalConfig{MyInts: []int{1}, MyMap: map[string]string{"a:b": "c"}})
+	assert.Error(t, err)
+	assert.Equal(t, `error marshaling "MY_MAP" (map entry "a:b" contains a separator)`, err.Error())
+
+	_, err = Marshal(&struct {
+		MyStrings []string `envvar:"MY_STRINGS"`
+	}{MyStrings: []string{"a,b"}})
+	assert.Error(t, err)
+	assert.Equal(t, `error marshaling "MY_STRINGS" (list item "a,b" contains the list separator)`,
+		err.Error())
+
+	_, err = Marshal(&struct {
+		MyInt     int `envvar:"MY_INT"`
+		MySameInt int `envvar:"MY_INT"`
+	}{MyInt: 1, MySameInt: 2})
+	assert.Error(t, err)
+	assert.Equal(t, `error marshaling "MY_INT" (fields have conflicting values "1" and "2")`,
+		err.Error())
+}
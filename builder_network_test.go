@@ -0,0 +1,113 @@
+package cfgbuild
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestNetworkConfig struct {
+	Addr      netip.Addr       `envvar:"ADDR"`
+	Gateway   *netip.Addr      `envvar:"GATEWAY"`
+	Subnet    netip.Prefix     `envvar:"SUBNET,default=10.0.0.0/8"`
+	Peer      netip.AddrPort   `envvar:"PEER"`
+	Trusted   *net.IPNet       `envvar:"TRUSTED"`
+	Resolvers []netip.Addr     `envvar:"RESOLVERS"`
+	Allowed   []netip.Prefix   `envvar:"ALLOWED"`
+	Peers     []netip.AddrPort `envvar:"PEERS"`
+	Networks  []*net.IPNet     `envvar:"NETWORKS"`
+	Listen    string           `envvar:"LISTEN,hostport,default=:8080"`
+	Upstreams []string         `envvar:"UPSTREAMS,hostport"`
+}
+
+func TestNetworkFields(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("ADDR", "192.168.1.10")
+	os.Setenv("GATEWAY", "fe80::1")
+	os.Setenv("PEER", "[::1]:8443")
+	os.Setenv("TRUSTED", "172.16.4.2/12")
+	os.Setenv("RESOLVERS", "1.1.1.1, 8.8.8.8")
+	os.Setenv("ALLOWED", "10.0.0.0/8,192.168.0.0/16")
+	os.Setenv("PEERS", "10.0.0.1:80,10.0.0.2:81")
+	os.Setenv("NETWORKS", "10.1.0.0/16,2001:db8::/32")
+	os.Setenv("UPSTREAMS", "db:5432, cache.local:6379")
+
+	cfg, err := NewConfig[*TestNetworkConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("192.168.1.10"), cfg.Addr)
+	assert.Equal(t, netip.MustParseAddr("fe80::1"), *cfg.Gateway)
+	assert.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), cfg.Subnet)
+	assert.Equal(t, netip.MustParseAddrPort("[::1]:8443"), cfg.Peer)
+	assert.Equal(t, "172.16.0.0/12", cfg.Trusted.String())
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("1.1.1.1"), netip.MustParseAddr("8.8.8.8")},
+		cfg.Resolvers)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.0.0/16")}, cfg.Allowed)
+	assert.Equal(t, []netip.AddrPort{netip.MustParseAddrPort("10.0.0.1:80"),
+		netip.MustParseAddrPort("10.0.0.2:81")}, cfg.Peers)
+	assert.Len(t, cfg.Networks, 2)
+	assert.Equal(t, "2001:db8::/32", cfg.Networks[1].String())
+	assert.Equal(t, ":8080", cfg.Listen)
+	assert.Equal(t, []string{"db:5432", "cache.local:6379"}, cfg.Upstreams)
+
+	m, err := Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "fe80::1", m["GATEWAY"])
+	assert.Equal(t, "172.16.0.0/12", m["TRUSTED"])
+	assert.Equal(t, "10.0.0.0/8,192.168.0.0/16", m["ALLOWED"])
+	assert.Equal(t, "10.1.0.0/16,2001:db8::/32", m["NETWORKS"])
+
+	os.Setenv("TRUSTED", "172.16.4.2")
+	_, err = NewConfig[*TestNetworkConfig]()
+	assert.EqualError(t, err, `error reading "TRUSTED" (invalid CIDR address: 172.16.4.2)`)
+	os.Unsetenv("TRUSTED")
+
+	os.Setenv("RESOLVERS", "1.1.1.1,one.one")
+	_, err = NewConfig[*TestNetworkConfig]()
+	assert.EqualError(t, err, `error reading "RESOLVERS" (ParseAddr("one.one"): unexpected character (at "one.one"))`)
+	os.Unsetenv("RESOLVERS")
+
+	os.Setenv("LISTEN", "localhost")
+	_, err = NewConfig[*TestNetworkConfig]()
+	assert.EqualError(t, err, `error reading "LISTEN" (address localhost: missing port in address)`)
+	os.Unsetenv("LISTEN")
+
+	os.Setenv("UPSTREAMS", "db:5432,cache:6379:1")
+	_, err = NewConfig[*TestNetworkConfig]()
+	assert.EqualError(t, err, `error reading "UPSTREAMS" (address cache:6379:1: too many colons in address)`)
+	os.Unsetenv("UPSTREAMS")
+
+	err = InitConfig(&struct {
+		Port int `envvar:"PORT,hostport"`
+	}{})
+	assert.EqualError(t, err, `the "hostport" attribute is only allowed on string fields`)
+
+	assert.EqualError(t, ValidateTag(">,hostport"),
+		`the "hostport" attribute is not allowed on ">" nested config fields`)
+}
+
+func TestGenericLists(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("FLAGS", "true,false")
+	os.Setenv("DURATIONS", "1s,2m")
+
+	cfg, err := NewConfig[*struct {
+		Flags     []bool          `envvar:"FLAGS"`
+		Durations []time.Duration `envvar:"DURATIONS"`
+	}]()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, cfg.Flags)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, cfg.Durations)
+
+	os.Setenv("CHANNELS", "a")
+	err = InitConfig(&struct {
+		Channels []chan int `envvar:"CHANNELS"`
+	}{})
+	assert.EqualError(t, err, `error reading "CHANNELS" (unsupported type/kind "[]chan int/slice")`)
+}
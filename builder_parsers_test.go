@@ -0,0 +1,188 @@
+package cfgbuild
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTicketID does not implement TextUnmarshaler so it can only be parsed by a registered parser.
+type TestTicketID struct {
+	Project string
+	Num     int
+}
+
+func parseTestTicketID(s string) (any, error) {
+	project, num, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, errors.New("ticket ID must be PROJECT-NUM")
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return nil, err
+	}
+	return TestTicketID{Project: project, Num: n}, nil
+}
+
+type TestParsersConfig struct {
+	Ticket   TestTicketID            `envvar:"TICKET,default=ABC-1"`
+	Tickets  []TestTicketID          `envvar:"TICKETS"`
+	Owners   map[TestTicketID]string `envvar:"OWNERS"`
+	Blockers map[string]TestTicketID `envvar:"BLOCKERS"`
+	Parent   *TestTicketID           `envvar:"PARENT"`
+	Timeout  time.Duration           `envvar:"TIMEOUT"`
+	Nested   TestParsersChildConfig  `envvar:">,prefix=CHILD_"`
+}
+
+type TestParsersChildConfig struct {
+	Ticket TestTicketID `envvar:"TICKET"`
+}
+
+func TestParsers(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("TICKETS", "ABC-2, XYZ-3")
+	os.Setenv("OWNERS", "ABC-2:alice,XYZ-3:bob")
+	os.Setenv("BLOCKERS", "alice:XYZ-3")
+	os.Setenv("PARENT", "ABC-4")
+	os.Setenv("TIMEOUT", "5")
+	os.Setenv("CHILD_TICKET", "XYZ-5")
+
+	b := Builder[*TestParsersConfig]{Parsers: map[reflect.Type]func(string) (any, error){
+		reflect.TypeOf(TestTicketID{}): parseTestTicketID,
+		// registered parsers replace the built-in parsing
+		reflect.TypeOf(time.Duration(0)): func(s string) (any, error) {
+			secs, err := strconv.Atoi(s)
+			return time.Duration(secs) * time.Second, err
+		},
+	}}
+
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, TestTicketID{"ABC", 1}, cfg.Ticket)
+	assert.Equal(t, []TestTicketID{{"ABC", 2}, {"XYZ", 3}}, cfg.Tickets)
+	assert.Equal(t, map[TestTicketID]string{{"ABC", 2}: "alice", {"XYZ", 3}: "bob"}, cfg.Owners)
+	assert.Equal(t, map[string]TestTicketID{"alice": {"XYZ", 3}}, cfg.Blockers)
+	assert.Equal(t, &TestTicketID{"ABC", 4}, cfg.Parent)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, TestTicketID{"XYZ", 5}, cfg.Nested.Ticket)
+
+	os.Setenv("PARENT", "ABC")
+	_, err = b.Build()
+	assert.EqualError(t, err, `error reading "PARENT" (ticket ID must be PROJECT-NUM)`)
+	os.Unsetenv("PARENT")
+
+	os.Setenv("OWNERS", "ABC-2")
+	_, err = b.Build()
+	assert.EqualError(t, err,
+		`error reading "OWNERS" (key/value pair must contain exactly one ":" separator)`)
+	os.Unsetenv("OWNERS")
+
+	// the value returned by the parser must be assignable to the type
+	b.Parsers[reflect.TypeOf(TestTicketID{})] = func(s string) (any, error) { return s, nil }
+	_, err = b.Build()
+	assert.EqualError(t, err,
+		`error setting default value for "TICKET" (parser for type cfgbuild.TestTicketID returned a string)`)
+
+	// without a parser the type isn't supported
+	b.Parsers = nil
+	_, err = b.Build()
+	assert.Error(t, err)
+}
+
+type TestDecodersConfig struct {
+	Key     []byte `envvar:"KEY,parser=base64"`
+	Salt    []byte `envvar:"SALT,parser=hex,default=0a0b"`
+	Token   string `envvar:"TOKEN,parser=base64url"`
+	Env     string `envvar:"ENV,parser=lower,oneof=dev|prod"`
+	Region  string `envvar:"REGION,parser=upper"`
+	Name    string `envvar:"NAME,parser=trim"`
+	Reverse string `envvar:"REVERSE,parser=reverse"`
+}
+
+func TestDecoders(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("KEY", "aGVsbG8=")
+	os.Setenv("TOKEN", "Pz8_")
+	os.Setenv("ENV", "PROD")
+	os.Setenv("REGION", "us-east")
+	os.Setenv("NAME", "  bob ")
+	os.Setenv("REVERSE", "abc")
+
+	reverse := func(s string) (string, error) {
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	}
+
+	// unknown parser names are rejected before the config is built
+	_, err := NewConfig[*TestDecodersConfig]()
+	assert.Error(t, err)
+	e, ok := err.(*TagSyntaxError)
+	assert.True(t, ok, "error should be a TagSyntaxError")
+	assert.Equal(t, "Reverse", e.FieldName)
+	assert.Equal(t, `unknown parser "reverse"`, e.Error())
+
+	b := Builder[*TestDecodersConfig]{Decoders: map[string]func(string) (string, error){
+		"reverse": reverse,
+	}}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), cfg.Key)
+	assert.Equal(t, []byte{10, 11}, cfg.Salt)
+	assert.Equal(t, "???", cfg.Token)
+	assert.Equal(t, "prod", cfg.Env)
+	assert.Equal(t, "US-EAST", cfg.Region)
+	assert.Equal(t, "bob", cfg.Name)
+	assert.Equal(t, "cba", cfg.Reverse)
+
+	os.Setenv("KEY", "not base64!")
+	_, err = b.Build()
+	assert.EqualError(t, err, `error reading "KEY" (illegal base64 data at input byte 3)`)
+
+	// values are marshaled so that the decoders return the field values
+	type roundTripConfig struct {
+		Key    []byte   `envvar:"KEY,parser=base64"`
+		Salt   []byte   `envvar:"SALT,parser=hex"`
+		Token  string   `envvar:"TOKEN,parser=base64url"`
+		Env    string   `envvar:"ENV,parser=lower,oneof=dev|prod"`
+		Names  []string `envvar:"NAMES,parser=base64"`
+		Region string   `envvar:"REGION,parser=upper"`
+	}
+	src := MapSource{"KEY": "aGVsbG8", "SALT": "0A0B", "TOKEN": "Pz8_", "ENV": "PROD",
+		"NAMES": base64.StdEncoding.EncodeToString([]byte("a,b")), "REGION": "us-east"}
+	rt := Builder[*roundTripConfig]{Sources: []Source{src}}
+	original, err := rt.Build()
+	assert.NoError(t, err)
+
+	m, err := rt.Marshal(original)
+	assert.NoError(t, err)
+	assert.Equal(t, "aGVsbG8=", m["KEY"])
+	assert.Equal(t, "0a0b", m["SALT"])
+	assert.Equal(t, "Pz8_", m["TOKEN"])
+
+	rebuilt, err := (&Builder[*roundTripConfig]{Sources: []Source{MapSource(m)}}).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, original, rebuilt)
+
+	// values from the Builder Decoders can't be encoded
+	os.Setenv("KEY", "aGVsbG8=")
+	cfg, err = b.Build()
+	assert.NoError(t, err)
+	_, err = b.Marshal(cfg)
+	assert.EqualError(t, err, `error marshaling "REVERSE" (the "reverse" decoder can't be reversed)`)
+
+	assert.EqualError(t, ValidateTag("-,parser"), `the "parser" attribute requires a value`)
+	assert.EqualError(t, ValidateTag(">,parser=hex"),
+		`the "parser" attribute is not allowed on ">" nested config fields`)
+}
@@ -0,0 +1,52 @@
+package cfgbuild
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestPlanConfig struct {
+	Name  string `envvar:"NAME,default=envvar" other:"OTHER_NAME,default=other"`
+	Count int    `envvar:"COUNT,required"`
+}
+
+func TestPlanCache(t *testing.T) {
+	typ := reflect.TypeOf(TestPlanConfig{})
+
+	b := Builder[*TestPlanConfig]{}
+	p := b.plan(typ)
+	assert.Same(t, p, b.plan(typ))
+	if assert.Len(t, p.fields, 2) {
+		assert.Equal(t, "NAME", p.fields[0].name)
+		assert.Equal(t, "envvar", p.fields[0].defaultVal)
+		assert.True(t, p.fields[1].required)
+	}
+
+	other := Builder[*TestPlanConfig]{TagKey: "other"}
+	op := other.plan(typ)
+	assert.NotSame(t, p, op)
+	if assert.Len(t, op.fields, 1) {
+		assert.Equal(t, "OTHER_NAME", op.fields[0].name)
+		assert.Equal(t, "other", op.fields[0].defaultVal)
+	}
+}
+
+func TestPlanConcurrentBuilds(t *testing.T) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := Builder[*BenchConfig]{Prefix: "APP_", Sources: []Source{
+				MapSource{"APP_PASSWORD": "hunter2", "APP_DB_USER": "admin", "APP_DB_PORT": "6543"}}}
+			cfg, err := b.Build()
+			assert.NoError(t, err)
+			assert.Equal(t, 6543, cfg.DB.Port)
+			assert.Equal(t, "localhost", cfg.Host)
+		}()
+	}
+	wg.Wait()
+}
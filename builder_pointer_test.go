@@ -1,6 +1,9 @@
 package cfgbuild
 
 import (
+	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"testing"
 	"time"
@@ -76,3 +79,73 @@ type TestPointerConfig struct {
 	MyString   *string        `envvar:"MY_STRING"`
 	MyBool     *bool          `envvar:"MY_BOOL"`
 }
+
+type TestPointerColor int
+
+func (c *TestPointerColor) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "red":
+		*c = 1
+	case "blue":
+		*c = 2
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+type TestPointerLevel uint8
+
+type TestGenericPointerConfig struct {
+	Time     *time.Time         `envvar:"PTR_TIME,layout=DateOnly"`
+	IP       *net.IP            `envvar:"PTR_IP"`
+	Color    *TestPointerColor  `envvar:"PTR_COLOR"`
+	Level    *TestPointerLevel  `envvar:"PTR_LEVEL,default=3"`
+	List     *[]string          `envvar:"PTR_LIST"`
+	Map      *map[string]string `envvar:"PTR_MAP"`
+	URL      *url.URL           `envvar:"PTR_URL"`
+	Double   **int              `envvar:"PTR_DOUBLE"`
+	Location *time.Location     `envvar:"PTR_LOCATION"`
+}
+
+func TestGenericPointers(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("PTR_TIME", "2024-01-02")
+	os.Setenv("PTR_IP", "10.0.0.1")
+	os.Setenv("PTR_COLOR", "blue")
+	os.Setenv("PTR_LIST", "a,b")
+	os.Setenv("PTR_MAP", "a:1,b:2")
+	os.Setenv("PTR_URL", "https://example.com/path")
+	os.Setenv("PTR_DOUBLE", "7")
+	os.Setenv("PTR_LOCATION", "UTC")
+
+	cfg, err := NewConfig[*TestGenericPointerConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), *cfg.Time)
+	assert.Equal(t, "10.0.0.1", cfg.IP.String())
+	assert.Equal(t, TestPointerColor(2), *cfg.Color)
+	assert.Equal(t, TestPointerLevel(3), *cfg.Level)
+	assert.Equal(t, []string{"a", "b"}, *cfg.List)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, *cfg.Map)
+	assert.Equal(t, "example.com", cfg.URL.Host)
+	assert.Equal(t, 7, **cfg.Double)
+	assert.Same(t, time.UTC, cfg.Location)
+
+	os.Setenv("PTR_COLOR", "green")
+	_, err = NewConfig[*TestGenericPointerConfig]()
+	assert.EqualError(t, err, `error reading "PTR_COLOR" (unknown color "green")`)
+
+	// pointer types which can't be parsed are reported instead of being ignored
+	os.Setenv("PTR_CHAN", "1")
+	_, err = NewConfig[*struct {
+		Chan *chan int `envvar:"PTR_CHAN"`
+	}]()
+	assert.EqualError(t, err, `error reading "PTR_CHAN" (unsupported type/kind "*chan int/ptr")`)
+
+	_, err = NewConfig[*struct {
+		Struct *struct{ A int } `envvar:"PTR_STRUCT,default=x"`
+	}]()
+	assert.EqualError(t, err,
+		`error setting default value for "PTR_STRUCT" (unsupported type/kind "*struct { A int }/ptr")`)
+}
@@ -0,0 +1,94 @@
+package cfgbuild
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestRegexpConfig struct {
+	Filter *regexp.Regexp   `envvar:"FILTER"`
+	Allow  regexp.Regexp    `envvar:"ALLOW,default=^/api/"`
+	Ignore []*regexp.Regexp `envvar:"IGNORE"`
+	Unset  *regexp.Regexp   `envvar:"UNSET"`
+}
+
+func TestRegexpFields(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("FILTER", `^/v[0-9]+/`)
+	os.Setenv("IGNORE", `\.png$,\.css$`)
+
+	cfg, err := NewConfig[*TestRegexpConfig]()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Filter.MatchString("/v2/users"))
+	assert.True(t, cfg.Allow.MatchString("/api/users"))
+	assert.Len(t, cfg.Ignore, 2)
+	assert.True(t, cfg.Ignore[1].MatchString("site.css"))
+	assert.Nil(t, cfg.Unset)
+
+	m, err := Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, `^/v[0-9]+/`, m["FILTER"])
+	assert.Equal(t, `^/api/`, m["ALLOW"])
+
+	schema, err := JSONSchema[*TestRegexpConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, "regex", schema.Properties["FILTER"].Format)
+
+	os.Setenv("FILTER", `^/v[0-9+/`)
+	_, err = NewConfig[*TestRegexpConfig]()
+	var parseErr *ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "FILTER", parseErr.Name)
+	assert.EqualError(t, err,
+		"error reading \"FILTER\" (error parsing regexp: missing closing ]: `[0-9+/`)")
+}
+
+func TestURLSchemes(t *testing.T) {
+	defer os.Clearenv()
+
+	type schemesConfig struct {
+		Callback url.URL   `envvar:"CALLBACK,schemes=http|HTTPS"`
+		Proxy    *url.URL  `envvar:"PROXY,schemes=socks5"`
+		Mirrors  []url.URL `envvar:"MIRRORS,schemes=https"`
+	}
+
+	os.Setenv("CALLBACK", "HTTPS://example.com/cb")
+	os.Setenv("PROXY", "socks5://localhost:1080")
+	os.Setenv("MIRRORS", "https://a.example.com,https://b.example.com")
+
+	cfg, err := NewConfig[*schemesConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, "https", cfg.Callback.Scheme)
+	assert.Equal(t, "localhost:1080", cfg.Proxy.Host)
+	assert.Len(t, cfg.Mirrors, 2)
+
+	schema, err := JSONSchema[*schemesConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, `^(http|https):[^\s]*$`, schema.Properties["CALLBACK"].Pattern)
+	assert.Equal(t, "uri", schema.Properties["CALLBACK"].Format)
+
+	os.Setenv("CALLBACK", "ftp://example.com")
+	_, err = NewConfig[*schemesConfig]()
+	assert.EqualError(t, err, `error reading "CALLBACK" (URL scheme "ftp" is not one of http, https)`)
+	os.Setenv("CALLBACK", "example.com")
+	_, err = NewConfig[*schemesConfig]()
+	assert.EqualError(t, err, `error reading "CALLBACK" (URL scheme "" is not one of http, https)`)
+	os.Setenv("CALLBACK", "http://example.com")
+
+	os.Setenv("MIRRORS", "https://a.example.com,http://b.example.com")
+	_, err = NewConfig[*schemesConfig]()
+	assert.EqualError(t, err, `error reading "MIRRORS" (URL scheme "http" is not one of https)`)
+
+	err = InitConfig(&struct {
+		Host string `envvar:"HOST,schemes=http"`
+	}{})
+	assert.EqualError(t, err, `the "schemes" attribute is only allowed on url.URL fields`)
+
+	assert.EqualError(t, ValidateTag("URL,schemes"), `the "schemes" attribute requires a value`)
+}
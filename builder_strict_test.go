@@ -0,0 +1,102 @@
+package cfgbuild
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestStrictConfig struct {
+	Name string              `envvar:"NAME"`
+	DB   TestStrictDBConfig  `envvar:">,prefix=DB_"`
+	Box  *TestStrictDBConfig `envvar:">"`
+}
+
+type TestStrictDBConfig struct {
+	Host string `envvar:"HOST"`
+	Port int    `envvar:"PORT"`
+}
+
+func TestStrictError(t *testing.T) {
+	src := MapSource{
+		"ORDERS_NAME":     "orders",
+		"ORDERS_DB_HSOT":  "localhost",
+		"ORDERS_DB_PORT":  "5432",
+		"ORDERS_UNKNOWN":  "?",
+		"OTHER_VARIABLE":  "ignored",
+		"ORDERS_HOST":     "used by Box",
+		"NOT_ORDERS_NAME": "ignored",
+	}
+
+	b := Builder[*TestStrictConfig]{Prefix: "ORDERS_", Sources: []Source{src}, Strict: StrictError}
+	_, err := b.Build()
+	assert.Error(t, err)
+	assert.Equal(t, `unknown variables "ORDERS_DB_HSOT" (did you mean "ORDERS_DB_HOST"?), "ORDERS_UNKNOWN"`,
+		err.Error())
+
+	unknownErr, ok := err.(*UnknownVarsError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"ORDERS_DB_HSOT", "ORDERS_UNKNOWN"}, unknownErr.Names)
+	assert.Equal(t, map[string]string{"ORDERS_DB_HSOT": "ORDERS_DB_HOST"}, unknownErr.Suggestions)
+
+	delete(src, "ORDERS_DB_HSOT")
+	delete(src, "ORDERS_UNKNOWN")
+	src["ORDERS_DB_HOST"] = "localhost"
+	cfg, err := b.fresh().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", cfg.Name)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, "used by Box", cfg.Box.Host)
+}
+
+func TestStrictWarn(t *testing.T) {
+	warnings := []error{}
+	b := Builder[*TestStrictConfig]{
+		Sources:   []Source{MapSource{"DB_PORTT": "1", "HOST": "localhost"}},
+		Strict:    StrictWarn,
+		OnWarning: func(err error) { warnings = append(warnings, err) },
+	}
+
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.Box.Host)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, `unknown variable "DB_PORTT" (did you mean "DB_PORT"?)`, warnings[0].Error())
+}
+
+func TestStrictNoPrefix(t *testing.T) {
+	// without any prefixes there is no way to know which variables belong to the config
+	b := Builder[*TestNestedConfig]{Sources: []Source{MapSource{"MY_VALL": "x"}}, Strict: StrictError}
+	_, err := b.Build()
+	assert.NoError(t, err)
+}
+
+func TestStrictEnvSource(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("STRICT_MY_VAL", "x")
+	os.Setenv("STRICT_MY_VAR", "x")
+
+	b := Builder[*TestNestedConfig]{Prefix: "STRICT_", Strict: StrictError}
+	_, err := b.Build()
+	assert.Error(t, err)
+	assert.Equal(t, `unknown variable "STRICT_MY_VAR" (did you mean "STRICT_MY_VAL"?)`, err.Error())
+}
+
+func TestRootPrefix(t *testing.T) {
+	b := Builder[*TestStrictConfig]{Prefix: "APP_", Sources: []Source{MapSource{
+		"APP_NAME": "app", "APP_DB_HOST": "db", "NAME": "not me",
+	}}}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, "db", cfg.DB.Host)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("HOST", "HOST"))
+	assert.Equal(t, 2, editDistance("HSOT", "HOST"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 4, editDistance("", "HOST"))
+}
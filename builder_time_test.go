@@ -0,0 +1,99 @@
+package cfgbuild
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestTimeConfig struct {
+	Default  time.Time      `envvar:"TIME_DEFAULT"`
+	RFC1123  time.Time      `envvar:"TIME_RFC1123,layout=RFC1123"`
+	Date     time.Time      `envvar:"TIME_DATE,layout=DateOnly,default=2024-01-02"`
+	Custom   time.Time      `envvar:"TIME_CUSTOM,layout=2006/01/02 15:04"`
+	Unix     time.Time      `envvar:"TIME_UNIX,layout=unix"`
+	UnixMs   *time.Time     `envvar:"TIME_UNIX_MS,layout=unixms"`
+	Pointer  *time.Time     `envvar:"TIME_POINTER"`
+	Location *time.Location `envvar:"TIME_LOCATION"`
+	Zone     time.Location  `envvar:"TIME_ZONE,default=UTC"`
+}
+
+func TestTimeLayouts(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("TIME_DEFAULT", "2024-01-02T03:04:05Z")
+	os.Setenv("TIME_RFC1123", "Tue, 02 Jan 2024 03:04:05 UTC")
+	os.Setenv("TIME_CUSTOM", "2024/01/02 03:04")
+	os.Setenv("TIME_UNIX", "1704164645")
+	os.Setenv("TIME_UNIX_MS", "1704164645123")
+	os.Setenv("TIME_POINTER", "2024-01-02T03:04:05Z")
+	os.Setenv("TIME_LOCATION", "America/New_York")
+
+	cfg, err := NewConfig[*TestTimeConfig]()
+	assert.NoError(t, err)
+
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.True(t, expected.Equal(cfg.Default))
+	assert.True(t, expected.Equal(cfg.RFC1123))
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), cfg.Date)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC), cfg.Custom)
+	assert.Equal(t, expected, cfg.Unix)
+	assert.Equal(t, expected.Add(123*time.Millisecond), *cfg.UnixMs)
+	assert.True(t, expected.Equal(*cfg.Pointer))
+	assert.Equal(t, "America/New_York", cfg.Location.String())
+	assert.Equal(t, "UTC", cfg.Zone.String())
+
+	// the values round trip through Marshal
+	m, err := Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02", m["TIME_DATE"])
+	assert.Equal(t, "1704164645", m["TIME_UNIX"])
+	assert.Equal(t, "1704164645123", m["TIME_UNIX_MS"])
+	assert.Equal(t, "America/New_York", m["TIME_LOCATION"])
+	assert.Equal(t, "UTC", m["TIME_ZONE"])
+
+	tst := func(envVar, val, expectedErr string) {
+		os.Setenv(envVar, val)
+		_, err := NewConfig[*TestTimeConfig]()
+		assert.EqualError(t, err, expectedErr)
+		os.Unsetenv(envVar)
+	}
+
+	tst("TIME_RFC1123", "2024-01-02", `error reading "TIME_RFC1123" (parsing time "2024-01-02" `+
+		`as "Mon, 02 Jan 2006 15:04:05 MST": cannot parse "2024-01-02" as "Mon" (layout RFC1123))`)
+	tst("TIME_UNIX", "yesterday",
+		`error reading "TIME_UNIX" (time "yesterday" does not match the unix layout (an integer))`)
+	tst("TIME_CUSTOM", "2024-01-02", `error reading "TIME_CUSTOM" (parsing time "2024-01-02" `+
+		`as "2006/01/02 15:04": cannot parse "-01-02" as "/")`)
+	tst("TIME_LOCATION", "Mars/Olympus_Mons",
+		`error reading "TIME_LOCATION" (unknown time zone Mars/Olympus_Mons)`)
+
+	err = InitConfig(&struct {
+		Start string `envvar:"START,layout=DateOnly"`
+	}{})
+	assert.EqualError(t, err, `the "layout" attribute is only allowed on time.Time fields`)
+}
+
+// TestLocalLocation checks that a time.Location field set to "Local" has the local time zone.
+// The test runs itself in a new process with TZ set since time.Local is only initialized once.
+func TestLocalLocation(t *testing.T) {
+	if os.Getenv("CFGBUILD_TEST_LOCAL_LOCATION") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLocalLocation$")
+		cmd.Env = append(os.Environ(), "CFGBUILD_TEST_LOCAL_LOCATION=1", "TZ=America/New_York")
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+		return
+	}
+
+	os.Setenv("TIME_ZONE", "Local")
+	defer os.Unsetenv("TIME_ZONE")
+
+	cfg, err := NewConfig[*TestTimeConfig]()
+	assert.NoError(t, err)
+
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2024-06-01T08:00:00-04:00", noon.In(&cfg.Zone).Format(time.RFC3339))
+}
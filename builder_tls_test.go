@@ -0,0 +1,255 @@
+package cfgbuild
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCertificate returns the PEM encoded self-signed certificate and private key for the name.
+func testCertificate(t *testing.T, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+type TestTLSConfig struct {
+	Server    tls.Certificate     `envvar:"SERVER_CERT,key=SERVER_KEY"`
+	Client    *tls.Certificate    `envvar:"CLIENT_PEM"`
+	ClientCAs *x509.CertPool      `envvar:"CLIENT_CAS"`
+	RootCAs   *x509.CertPool      `envvar:"ROOT_CAS,file"`
+	Pinned    []*x509.Certificate `envvar:"PINNED"`
+}
+
+func TestTLSFields(t *testing.T) {
+	defer os.Clearenv()
+
+	serverCert, serverKey := testCertificate(t, "server")
+	clientCert, clientKey := testCertificate(t, "client")
+	rootCert, _ := testCertificate(t, "root")
+
+	dir := t.TempDir()
+	rootFile := filepath.Join(dir, "root.pem")
+	assert.NoError(t, os.WriteFile(rootFile, []byte(rootCert), 0600))
+
+	os.Setenv("SERVER_CERT", serverCert)
+	os.Setenv("SERVER_KEY", serverKey)
+	os.Setenv("CLIENT_PEM", clientCert+clientKey)
+	os.Setenv("CLIENT_CAS", clientCert)
+	os.Setenv("ROOT_CAS", rootFile)
+	os.Setenv("PINNED", serverCert+rootCert)
+
+	cfg, err := NewConfig[*TestTLSConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, "server", cfg.Server.Leaf.Subject.CommonName)
+	assert.NotNil(t, cfg.Server.PrivateKey)
+	assert.Equal(t, "client", cfg.Client.Leaf.Subject.CommonName)
+	assert.NotNil(t, cfg.Client.PrivateKey)
+	assert.Len(t, cfg.Pinned, 2)
+	assert.Equal(t, "root", cfg.Pinned[1].Subject.CommonName)
+
+	// the pools hold the certificates
+	_, err = cfg.Client.Leaf.Verify(x509.VerifyOptions{Roots: cfg.ClientCAs})
+	assert.NoError(t, err)
+	_, err = cfg.Pinned[1].Verify(x509.VerifyOptions{Roots: cfg.RootCAs})
+	assert.NoError(t, err)
+	_, err = cfg.Pinned[1].Verify(x509.VerifyOptions{Roots: cfg.ClientCAs})
+	assert.Error(t, err)
+
+	// private keys and pools aren't marshaled
+	m, err := Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"PINNED": serverCert + rootCert}, m)
+
+	// the key env var is known in strict mode
+	b := Builder[*TestTLSConfig]{Strict: StrictError, Sources: []Source{MapSource{
+		"SERVER_CERT": serverCert, "SERVER_KEY": serverKey}}}
+	_, err = b.Build()
+	assert.NoError(t, err)
+
+	os.Setenv("SERVER_CERT", "not a certificate")
+	_, err = NewConfig[*TestTLSConfig]()
+	assert.EqualError(t, err, `error reading "SERVER_CERT" (no PEM certificates found)`)
+	os.Setenv("SERVER_CERT", serverCert)
+
+	os.Setenv("CLIENT_PEM", clientCert)
+	_, err = NewConfig[*TestTLSConfig]()
+	assert.EqualError(t, err, `error reading "CLIENT_PEM" (no PEM private key found)`)
+	os.Setenv("CLIENT_PEM", clientCert+clientKey)
+
+	os.Unsetenv("SERVER_KEY")
+	_, err = NewConfig[*TestTLSConfig]()
+	assert.EqualError(t, err, `error reading "SERVER_KEY" (the private key is not set)`)
+	os.Setenv("SERVER_KEY", clientKey)
+	_, err = NewConfig[*TestTLSConfig]()
+	assert.EqualError(t, err,
+		`error reading "SERVER_CERT" (tls: private key does not match public key)`)
+	os.Setenv("SERVER_KEY", serverKey)
+
+	os.Setenv("CLIENT_CAS", clientKey)
+	_, err = NewConfig[*TestTLSConfig]()
+	assert.EqualError(t, err, `error reading "CLIENT_CAS" (no PEM certificates found)`)
+	os.Setenv("CLIENT_CAS", clientCert)
+
+	os.Setenv("ROOT_CAS", filepath.Join(dir, "missing.pem"))
+	_, err = NewConfig[*TestTLSConfig]()
+	var parseErr *ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "ROOT_CAS", parseErr.Name)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestTLSFiles(t *testing.T) {
+	defer os.Clearenv()
+
+	cert, key := testCertificate(t, "server")
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	assert.NoError(t, os.WriteFile(certFile, []byte(cert), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, []byte(key), 0600))
+
+	type fileConfig struct {
+		Cert tls.Certificate `envvar:"TLS_CERT,key=TLS_KEY,file,required"`
+		Name string          `envvar:"NAME,file"`
+		Port int             `envvar:"PORT_FILE,file"`
+	}
+
+	// a trailing newline is removed from the file contents
+	nameFile := filepath.Join(dir, "name")
+	assert.NoError(t, os.WriteFile(nameFile, []byte("server\n"), 0600))
+	portFile := filepath.Join(dir, "port")
+	assert.NoError(t, os.WriteFile(portFile, []byte("8080\r\n"), 0600))
+
+	os.Setenv("TLS_CERT", certFile)
+	os.Setenv("TLS_KEY", keyFile)
+	os.Setenv("NAME", nameFile)
+	os.Setenv("PORT_FILE", portFile)
+
+	cfg, err := NewConfig[*fileConfig]()
+	assert.NoError(t, err)
+	assert.Equal(t, "server", cfg.Cert.Leaf.Subject.CommonName)
+	assert.Equal(t, "server", cfg.Name)
+	assert.Equal(t, 8080, cfg.Port)
+
+	// values read from files aren't marshaled
+	m, err := Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, (&Builder[*fileConfig]{}).WriteEnvExample(buf))
+	assert.Contains(t, buf.String(), "# Cert (tls.Certificate from file, required)\nTLS_CERT=\n\n"+
+		"# Cert private key (PEM file, required)\nTLS_KEY=\n")
+
+	schema, err := JSONSchema[*fileConfig]()
+	assert.NoError(t, err)
+	assert.Contains(t, schema.Required, "TLS_KEY")
+	// the values are file names
+	assert.Equal(t, &SchemaProperty{Type: "string"}, schema.Properties["PORT_FILE"])
+
+	os.Setenv("TLS_KEY", filepath.Join(dir, "missing.key"))
+	_, err = NewConfig[*fileConfig]()
+	var parseErr *ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "TLS_KEY", parseErr.Name)
+
+	err = InitConfig(&struct {
+		Cert string `envvar:"CERT,key=KEY"`
+	}{})
+	assert.EqualError(t, err, `the "key" attribute is only allowed on tls.Certificate fields`)
+
+	assert.EqualError(t, ValidateTag("CERT,key=KEY,default=cert.pem"),
+		`the "default" and "key" attributes may not be used together`)
+	assert.EqualError(t, ValidateTag(">,file"),
+		`the "file" attribute is not allowed on ">" nested config fields`)
+}
+
+func TestTLSRedaction(t *testing.T) {
+	defer os.Clearenv()
+
+	cert, key := testCertificate(t, "server")
+	// CLIENT_PEM is a combined PEM without the "key" attribute
+	os.Setenv("CLIENT_PEM", cert+key)
+	os.Setenv("SERVER_CERT", cert)
+	os.Setenv("SERVER_KEY", key)
+	os.Setenv("KEY_TEXT", key)
+
+	buf := &bytes.Buffer{}
+	b := Builder[*TestTLSConfig]{
+		Logger: slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+	_, err := b.Build()
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "PRIVATE KEY")
+	assert.Contains(t, buf.String(), RedactedValue)
+
+	// any value with a private key block is redacted
+	buf.Reset()
+	_, err = (&Builder[*struct {
+		KeyText string `envvar:"KEY_TEXT"`
+	}]{Logger: b.Logger}).Build()
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "PRIVATE KEY")
+}
+
+func TestTLSReload(t *testing.T) {
+	cert, _ := testCertificate(t, "ca")
+	other, _ := testCertificate(t, "other")
+
+	type reloadConfig struct {
+		CAs    *x509.CertPool      `envvar:"CAS,static"`
+		Pinned []*x509.Certificate `envvar:"PINNED,static"`
+		Level  string              `envvar:"LVL"`
+	}
+
+	src := MapSource{"CAS": cert, "PINNED": cert, "LVL": "info"}
+	b := Builder[*reloadConfig]{Sources: []Source{src}}
+	current, err := b.Build()
+	assert.NoError(t, err)
+
+	// the pool and certificates are rebuilt from the same PEM so they haven't changed
+	src["LVL"] = "debug"
+	_, diff, err := b.Reload(context.Background(), current)
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldChange{{Path: "Level", EnvVar: "LVL", Old: "info", New: "debug"}}, diff)
+
+	src["CAS"] = other
+	_, _, err = b.Reload(context.Background(), current)
+	assert.EqualError(t, err, "restart required to change CAS")
+
+	src["CAS"] = cert
+	src["PINNED"] = other
+	_, _, err = b.Reload(context.Background(), current)
+	assert.EqualError(t, err, "restart required to change PINNED")
+}
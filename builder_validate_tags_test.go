@@ -1,6 +1,7 @@
 package cfgbuild
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -62,3 +63,74 @@ func TestValidateConfigTags(t *testing.T) {
 		`the "required" attribute may not have a value`)
 
 }
+
+func TestValidateTag(t *testing.T) {
+	assert.NoError(t, ValidateTag("MY_INT,default=7,required"))
+	assert.NoError(t, ValidateTag(">,prefix=CHILD_"))
+	assert.NoError(t, ValidateTag("DB_HOST|DB_HOSTNAME,oneof=a|b"))
+
+	assert.EqualError(t, ValidateTag("-,required"),
+		`the "required" attribute is not allowed on "-" fields`)
+	assert.EqualError(t, ValidateTag("MY_INT,prefix=CHILD_"),
+		`the "prefix" attribute is only allowed on ">" nested config fields`)
+	assert.EqualError(t, ValidateTag("MY_INT,ninja"),
+		`tag value contains non-existent attribute "ninja"`)
+	assert.EqualError(t, ValidateTag(",required"), `tag does not have the name attribute set`)
+}
+
+type recursiveConfig struct {
+	Name  string           `envvar:"NAME"`
+	Child *recursiveConfig `envvar:">,prefix=CHILD_"`
+}
+
+type indirectParentConfig struct {
+	Child indirectChildConfig `envvar:">,prefix=CHILD_"`
+}
+
+type indirectChildConfig struct {
+	Parent *indirectParentConfig `envvar:">,prefix=PARENT_"`
+}
+
+type badNestedConfig struct {
+	initCalled bool
+	Nested     struct {
+		MyInt int `envvar:"MY_INT,ninja"`
+	} `envvar:">"`
+}
+
+func (cfg *badNestedConfig) CfgBuildInit() error {
+	cfg.initCalled = true
+	return nil
+}
+
+func TestValidateNestedConfigTags(t *testing.T) {
+	defer os.Clearenv()
+
+	// nested tag errors are found before the CfgBuildInit() function of the parent is run
+	b := Builder[*badNestedConfig]{}
+	cfg, err := b.Build()
+	assert.EqualError(t, err, `tag value contains non-existent attribute "ninja"`)
+	assert.False(t, cfg.initCalled)
+
+	tst := func(cfg interface{}, expectedFieldName, expectedMsg string) {
+		err := InitConfig(cfg)
+		assert.Error(t, err)
+		e, ok := err.(*TagSyntaxError)
+		assert.True(t, ok, "error should be a TagSyntaxError")
+		assert.Equal(t, expectedFieldName, e.FieldName)
+		assert.Equal(t, expectedMsg, e.msg)
+	}
+
+	os.Setenv("CHILD_NAME", "child")
+	tst(&recursiveConfig{}, "Child", "nested config type cfgbuild.recursiveConfig is recursive")
+	tst(&indirectParentConfig{}, "Parent",
+		"nested config type cfgbuild.indirectParentConfig is recursive")
+
+	tst(&struct {
+		MyInt int `envvar:">"`
+	}{}, "MyInt", `the ">" env var name is only allowed on struct and struct pointer fields`)
+
+	// the walker used by Diff() and Marshal() doesn't loop forever on recursive types
+	changes := Diff(&recursiveConfig{Name: "a"}, &recursiveConfig{Name: "b"})
+	assert.Len(t, changes, 1)
+}
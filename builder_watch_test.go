@@ -0,0 +1,225 @@
+package cfgbuild
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestWatchConfig struct {
+	MyInt    int              `envvar:"MY_INT"`
+	MyString string           `envvar:"MY_STRING"`
+	MyChild  TestNestedConfig `envvar:">,prefix=CHILD_"`
+}
+
+func (cfg *TestWatchConfig) CfgBuildValidate() error {
+	if cfg.MyInt < 0 {
+		return errors.New("MY_INT may not be negative")
+	}
+	return nil
+}
+
+func writeTestFile(t *testing.T, path, contents string, modTime time.Time) {
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	writeTestFile(t, path, `# comment
+MY_INT=42
+export MY_STRING="Nobody expects the \"Spanish\" Inquisition!"
+
+CHILD_MY_VAL='single quoted' # a comment
+`, time.Now())
+
+	b := Builder[*TestWatchConfig]{Sources: []Source{&FileSource{Path: path}}}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, cfg.MyInt)
+	assert.Equal(t, `Nobody expects the "Spanish" Inquisition!`, cfg.MyString)
+	assert.Equal(t, "single quoted", cfg.MyChild.MyVal)
+
+	writeTestFile(t, path, "MY_INT", time.Now().Add(time.Second))
+	_, err = b.fresh().Build()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't separate key from value")
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "MY_INT"), "42\n", time.Now())
+	writeTestFile(t, filepath.Join(dir, "CHILD_MY_VAL"), "child", time.Now())
+
+	b := Builder[*TestWatchConfig]{Sources: []Source{DirSource{Dir: dir}}}
+	cfg, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, cfg.MyInt)
+	assert.Equal(t, "", cfg.MyString)
+	assert.Equal(t, "child", cfg.MyChild.MyVal)
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	start := time.Now()
+	writeTestFile(t, path, "MY_INT=42\nMY_STRING=before\n", start)
+
+	changes := make(chan Change[*TestWatchConfig], 1)
+	errs := make(chan error, 1)
+
+	w := Watcher[*TestWatchConfig]{
+		Builder:  &Builder[*TestWatchConfig]{Sources: []Source{&FileSource{Path: path}}},
+		Interval: 5 * time.Millisecond,
+		OnChange: func(c Change[*TestWatchConfig]) { changes <- c },
+		OnError:  func(err error) { errs <- err },
+	}
+
+	assert.Nil(t, w.Current())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	assert.Eventually(t, func() bool { return w.Current() != nil }, time.Second, time.Millisecond)
+	first := w.Current()
+	assert.Equal(t, 42, first.MyInt)
+
+	// An invalid config is reported and the current config is kept
+	writeTestFile(t, path, "MY_INT=-1\nMY_STRING=before\n", start.Add(time.Second))
+	select {
+	case err := <-errs:
+		assert.Equal(t, "MY_INT may not be negative", err.Error())
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for error")
+	}
+	assert.Same(t, first, w.Current())
+
+	writeTestFile(t, path, "MY_INT=42\nMY_STRING=after\n", start.Add(2*time.Second))
+	select {
+	case c := <-changes:
+		assert.Same(t, first, c.Old)
+		assert.Equal(t, "after", c.New.MyString)
+		assert.Equal(t, []FieldChange{
+			{Path: "MyString", EnvVar: "MY_STRING", Old: "before", New: "after"},
+		}, c.Diff)
+		assert.Same(t, c.New, w.Current())
+	case <-time.After(time.Second):
+		assert.Fail(t, "timed out waiting for change")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcherSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	start := time.Now()
+	writeTestFile(t, path, "MY_STRING=before\n", start)
+
+	// the signal is also delivered here so that it can't stop the test process before the
+	// Watcher is notified
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	w := Watcher[*TestWatchConfig]{
+		Builder:  &Builder[*TestWatchConfig]{Sources: []Source{&FileSource{Path: path}}},
+		Interval: time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	assert.Eventually(t, func() bool { return w.Current() != nil }, time.Second, time.Millisecond)
+
+	// the file change is only noticed when SIGHUP is received
+	writeTestFile(t, path, "MY_STRING=after\n", start.Add(time.Second))
+	proc, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, proc.Signal(syscall.SIGHUP))
+		return w.Current().MyString == "after"
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcherReload(t *testing.T) {
+	src := MapSource{"MY_INT": "1"}
+	changed := 0
+
+	w := Watcher[*TestWatchConfig]{
+		Builder:  &Builder[*TestWatchConfig]{Sources: []Source{src}},
+		OnChange: func(c Change[*TestWatchConfig]) { changed++ },
+	}
+
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.Equal(t, 0, changed)
+
+	src["CHILD_MY_VAL"] = "child"
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, "child", w.Current().MyChild.MyVal)
+}
+
+type TestStaticConfig struct {
+	Port     int    `envvar:"PORT,static"`
+	LogLevel string `envvar:"LOG_LEVEL"`
+}
+
+func TestBuilderReloadStatic(t *testing.T) {
+	src := MapSource{"PORT": "8080", "LOG_LEVEL": "info"}
+	b := Builder[*TestStaticConfig]{Sources: []Source{src}}
+
+	current, err := b.Build()
+	assert.NoError(t, err)
+
+	src["LOG_LEVEL"] = "debug"
+	newCfg, diff, err := b.Reload(context.Background(), current)
+	assert.NoError(t, err)
+	assert.NotSame(t, current, newCfg)
+	assert.Equal(t, "info", current.LogLevel)
+	assert.Equal(t, "debug", newCfg.LogLevel)
+	assert.Equal(t, []FieldChange{
+		{Path: "LogLevel", EnvVar: "LOG_LEVEL", Old: "info", New: "debug"},
+	}, diff)
+
+	src["PORT"] = "9090"
+	newCfg, diff, err = b.Reload(context.Background(), current)
+	assert.Error(t, err)
+	assert.Equal(t, "restart required to change PORT", err.Error())
+	restartErr, ok := err.(*RestartRequiredError)
+	assert.True(t, ok)
+	assert.Equal(t, []FieldChange{
+		{Path: "Port", EnvVar: "PORT", Old: 8080, New: 9090, Static: true},
+	}, restartErr.Changes)
+	assert.Len(t, diff, 2)
+	assert.Equal(t, 9090, newCfg.Port)
+}
+
+func TestWatcherReloadStatic(t *testing.T) {
+	src := MapSource{"PORT": "8080", "LOG_LEVEL": "info"}
+	w := Watcher[*TestStaticConfig]{Builder: &Builder[*TestStaticConfig]{Sources: []Source{src}}}
+
+	assert.NoError(t, w.Reload(context.Background()))
+
+	src["PORT"] = "9090"
+	src["LOG_LEVEL"] = "debug"
+	err := w.Reload(context.Background())
+	assert.Error(t, err)
+	assert.IsType(t, &RestartRequiredError{}, err)
+
+	// the new config is rejected
+	assert.Equal(t, 8080, w.Current().Port)
+	assert.Equal(t, "info", w.Current().LogLevel)
+}
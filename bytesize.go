@@ -0,0 +1,183 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// unitBytes is the value of the "unit" tag attribute for integer fields which are set using byte
+// size units.
+const unitBytes = "bytes"
+
+// A ByteSize is a number of bytes.  It can be set using SI (ie "10MB") or IEC (ie "512KiB")
+// units and is formatted using the largest unit which exactly divides the size.  A ByteSize field
+// (or pointer or slice item) can be set using any value accepted by ParseByteSize.
+type ByteSize uint64
+
+// Common byte sizes.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+	PB          = 1000 * TB
+	EB          = 1000 * PB
+
+	KiB ByteSize = 1 << 10
+	MiB          = KiB << 10
+	GiB          = MiB << 10
+	TiB          = GiB << 10
+	PiB          = TiB << 10
+	EiB          = PiB << 10
+)
+
+// byteUnits are the units used when formatting a ByteSize (largest first).
+var byteUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"EiB", EiB}, {"EB", EB}, {"PiB", PiB}, {"PB", PB}, {"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB}, {"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"kB", KB},
+}
+
+// byteUnitSizes are the (lowercase) units accepted when parsing a ByteSize.  The "B" suffix is
+// optional.
+var byteUnitSizes = map[string]ByteSize{
+	"": Byte, "b": Byte,
+	"k": KB, "kb": KB, "ki": KiB, "kib": KiB,
+	"m": MB, "mb": MB, "mi": MiB, "mib": MiB,
+	"g": GB, "gb": GB, "gi": GiB, "gib": GiB,
+	"t": TB, "tb": TB, "ti": TiB, "tib": TiB,
+	"p": PB, "pb": PB, "pi": PiB, "pib": PiB,
+	"e": EB, "eb": EB, "ei": EiB, "eib": EiB,
+}
+
+// ParseByteSize parses a number of bytes with an optional SI (ie "10MB" or "1.5G") or IEC (ie
+// "512KiB") unit.  Units are case insensitive and SI units are powers of 1000 while IEC units are
+// powers of 1024.  A fractional number is allowed as long as the result is a whole number of
+// bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	num := strings.TrimRightFunc(s, unicode.IsLetter)
+	unit := strings.ToLower(s[len(num):])
+	num = strings.TrimSpace(num)
+
+	size, ok := byteUnitSizes[unit]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	if u, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if u > math.MaxUint64/uint64(size) {
+			return 0, errors.New("overflow error")
+		}
+		return ByteSize(u) * size, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	f *= float64(size)
+	if f >= math.MaxUint64 {
+		return 0, errors.New("overflow error")
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("byte size %q is not a whole number of bytes", s)
+	}
+	return ByteSize(f), nil
+}
+
+// String returns the size using the largest unit which exactly divides it (ie "512KiB" or
+// "10MB").  Sizes which aren't a multiple of a unit are returned as a number of bytes (ie "1500B").
+func (b ByteSize) String() string {
+	for _, u := range byteUnits {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// setBytes sets the integer (or integer pointer) v to the number of bytes in s.
+func setBytes(v reflect.Value, s string) error {
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Pointer {
+		p := reflect.New(v.Type().Elem())
+		if err := setBytes(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if size > math.MaxInt64 || v.OverflowInt(int64(size)) {
+			return errors.New("overflow error")
+		}
+		v.SetInt(int64(size))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.OverflowUint(uint64(size)) {
+			return errors.New("overflow error")
+		}
+		v.SetUint(uint64(size))
+	default:
+		return fmt.Errorf("unsupported type/kind \"%s/%s\" for byte sizes", v.Type().String(),
+			v.Kind().String())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// envExampleOptions are the settings of the env-example subcommand.
+type envExampleOptions struct {
+	// dir is the directory of the package containing the config type
+	dir      string
+	typeName string
+	prefix   string
+	tagKey   string
+}
+
+// envExampleProgram is a program which writes the .env.example file for the config type using
+// cfgbuild.Builder.WriteEnvExample() so that the output is the same as the library's.
+var envExampleProgram = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/NathanBak/cfgbuild"
+	config {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	b := cfgbuild.Builder[*config.{{.TypeName}}]{Prefix: {{printf "%q" .Prefix}}, TagKey: {{printf "%q" .TagKey}}}
+	if err := b.WriteEnvExample(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+// envExample returns the .env.example file for the config type.  The config package is imported
+// by a temporary program which is run with "go run" in the package directory (so the package's
+// module and the internal package rules apply).
+func envExample(opts envExampleOptions) ([]byte, error) {
+	out, err := goCommand(opts.dir, "list", "-f", "{{.ImportPath}} {{.Name}}", ".")
+	if err != nil {
+		return nil, err
+	}
+	importPath, pkgName, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	if pkgName == "main" {
+		return nil, fmt.Errorf("package %s is a command and can't be imported", importPath)
+	}
+
+	dir, err := filepath.Abs(opts.dir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(dir, ".cfgbuild-env-example-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	src := &bytes.Buffer{}
+	err = envExampleProgram.Execute(src, map[string]string{
+		"ImportPath": importPath,
+		"TypeName":   opts.typeName,
+		"Prefix":     opts.prefix,
+		"TagKey":     opts.tagKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mainFile := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(mainFile, src.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+
+	return goCommand(dir, "run", mainFile)
+}
+
+// goCommand runs the go command in dir and returns the output.  The error includes the standard
+// error output of the command.
+func goCommand(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("go %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("go %s: %v", args[0], err)
+	}
+	return out, nil
+}
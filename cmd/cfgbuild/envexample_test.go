@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/NathanBak/cfgbuild"
+	"github.com/NathanBak/cfgbuild/cmd/cfgbuild/internal/fixture"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEnvExampleFixture checks that the subcommand writes the same file as the library.
+func TestEnvExampleFixture(t *testing.T) {
+	opts := envExampleOptions{
+		dir:      filepath.Join("internal", "fixture"),
+		typeName: "Config",
+		prefix:   "APP_",
+		tagKey:   "envvar",
+	}
+
+	want := &bytes.Buffer{}
+	b := cfgbuild.Builder[*fixture.Config]{Prefix: "APP_"}
+	assert.NoError(t, b.WriteEnvExample(want))
+
+	got, err := envExample(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, want.String(), string(got))
+
+	opts.typeName = "Missing"
+	_, err = envExample(opts)
+	assert.ErrorContains(t, err, "undefined: config.Missing")
+
+	opts.dir = "."
+	opts.typeName = "Config"
+	_, err = envExample(opts)
+	assert.EqualError(t, err,
+		"package github.com/NathanBak/cfgbuild/cmd/cfgbuild is a command and can't be imported")
+}
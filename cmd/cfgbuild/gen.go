@@ -0,0 +1,796 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// genOptions are the options for the gen subcommand.
+type genOptions struct {
+	dir               string
+	types             []string
+	output            string
+	prefix            string
+	tagKey            string
+	listSeparator     string
+	keyValueSeparator string
+}
+
+// knownAttrs are the tag attributes understood by the generator.  Attributes which only affect
+// other cfgbuild features (such as "description" and "secret") are accepted but ignored.
+var knownAttrs = map[string]bool{
+	"allowEmpty":    true,
+	"default":       true,
+	"description":   true,
+	"notempty":      true,
+	"oneof":         true,
+	"prefix":        true,
+	"required":      true,
+	"secret":        true,
+	"static":        true,
+	"unmarshalJSON": true,
+	"unsetIfEmpty":  true,
+}
+
+// unsupportedAttrs are valid tag attributes which the generator can't reproduce (such as those
+// which depend on the Builder settings).
+var unsupportedAttrs = map[string]bool{
+	"base":     true,
+	"file":     true,
+	"hostport": true,
+	"key":      true,
+	"layout":   true,
+	"parser":   true,
+	"schemes":  true,
+	"unit":     true,
+}
+
+// A generator creates the loader functions for config types.
+type generator struct {
+	opts    genOptions
+	pkgName string
+	// specs are the type declarations of the package by name
+	specs map[string]typeSpec
+	// imports are the paths of the packages used by the generated code
+	imports map[string]bool
+	// helpers are the names of the helper functions used by the generated code
+	helpers map[string]bool
+	// done are the types whose loaders have been generated (or queued)
+	done  map[string]bool
+	queue []string
+	// setFields are the fields (Type.Field) which have a case in the set field function
+	setFields map[string]bool
+	buf       bytes.Buffer
+}
+
+// A typeSpec is a type declaration along with the file that contains it.
+type typeSpec struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// A genField is a tagged field of a config struct.
+type genField struct {
+	name     string
+	typ      ast.Expr
+	file     *ast.File
+	tagValue string
+	envName  string
+	aliases  []string
+	attrs    map[string]string
+}
+
+func (f genField) attr(name string) (string, bool) {
+	val, ok := f.attrs[name]
+	return val, ok
+}
+
+// generate returns the formatted source of the generated file.
+func generate(opts genOptions) ([]byte, error) {
+	g := &generator{
+		opts:      opts,
+		specs:     map[string]typeSpec{},
+		imports:   map[string]bool{},
+		helpers:   map[string]bool{},
+		done:      map[string]bool{},
+		setFields: map[string]bool{},
+	}
+
+	if err := g.parsePackage(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range opts.types {
+		if err := g.genLoadFunc(name); err != nil {
+			return nil, err
+		}
+	}
+	for len(g.queue) > 0 {
+		name := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.genTypeLoader(name); err != nil {
+			return nil, err
+		}
+	}
+
+	g.genHelpers()
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated by cfgbuild gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(out, "package %s\n\n", g.pkgName)
+
+	paths := []string{}
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintf(out, "import (\n")
+	for _, path := range paths {
+		fmt.Fprintf(out, "\t%q\n", path)
+	}
+	fmt.Fprintf(out, ")\n\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code (%s)", err)
+	}
+	return src, nil
+}
+
+// parsePackage reads the type declarations of the package in the directory.
+func (g *generator) parsePackage() error {
+	entries, err := os.ReadDir(g.opts.dir)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(g.opts.dir, name)
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") ||
+			filepath.Clean(path) == filepath.Clean(g.opts.output) {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		g.pkgName = file.Name.Name
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.specs[ts.Name.Name] = typeSpec{spec: ts, file: file}
+			}
+		}
+	}
+
+	if g.pkgName == "" {
+		return fmt.Errorf("no Go files found in %q", g.opts.dir)
+	}
+	return nil
+}
+
+// structFields returns the tagged fields of the named struct type.
+func (g *generator) structFields(typeName string) ([]genField, error) {
+	ts, ok := g.specs[typeName]
+	if !ok {
+		return nil, fmt.Errorf("type %q not found", typeName)
+	}
+	st, ok := ts.spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %q is not a struct", typeName)
+	}
+
+	fields := []genField{}
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		tagValue, ok := reflect.StructTag(tag).Lookup(g.opts.tagKey)
+		if !ok {
+			continue
+		}
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded field %s may not have the %q tag set", typeName,
+				types.ExprString(field.Type), g.opts.tagKey)
+		}
+
+		for _, ident := range field.Names {
+			f := genField{
+				name:     ident.Name,
+				typ:      field.Type,
+				file:     ts.file,
+				tagValue: tagValue,
+				attrs:    map[string]string{},
+			}
+
+			parts := strings.Split(tagValue, ",")
+			names := strings.Split(parts[0], "|")
+			f.envName, f.aliases = names[0], names[1:]
+			for _, part := range parts[1:] {
+				name, value, _ := strings.Cut(part, "=")
+				if unsupportedAttrs[name] {
+					return nil, fmt.Errorf("%s.%s: the %q attribute is not supported by the generator",
+						typeName, f.name, name)
+				}
+				if !knownAttrs[name] {
+					return nil, fmt.Errorf("%s.%s: tag value contains non-existent attribute %q",
+						typeName, f.name, name)
+				}
+				if _, ok := f.attrs[name]; !ok {
+					f.attrs[name] = value
+				}
+			}
+
+			if err := g.checkField(typeName, f); err != nil {
+				return nil, err
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// checkField returns an error for tags which the cfgbuild.Builder would reject.
+func (g *generator) checkField(typeName string, f genField) error {
+	var msg string
+	_, hasPrefix := f.attr("prefix")
+	_, hasDefault := f.attr("default")
+	_, hasRequired := f.attr("required")
+
+	switch {
+	case unicode.IsLower([]rune(f.name)[0]):
+		msg = "non-public fields may not have the tag set"
+	case f.envName == "":
+		msg = "tag does not have the name attribute set"
+	case f.envName != ">" && hasPrefix:
+		msg = `the "prefix" attribute is only allowed on ">" nested config fields`
+	case f.envName == ">" && hasDefault:
+		msg = `the "default" attribute is not allowed on ">" nested config fields`
+	case f.envName == "-" && hasRequired:
+		msg = `the "required" attribute is not allowed on "-" fields`
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s.%s: %s", typeName, f.name, msg)
+}
+
+// genLoadFunc writes the exported Load function for a config type.
+func (g *generator) genLoadFunc(typeName string) error {
+	if _, ok := g.specs[typeName]; !ok {
+		return fmt.Errorf("type %q not found", typeName)
+	}
+	g.enqueue(typeName)
+
+	w := &g.buf
+	fmt.Fprintf(w, "// Load%s creates and initializes a new %s using lookup (such as os.LookupEnv) to\n",
+		exportedName(typeName), typeName)
+	fmt.Fprintf(w, "// read the environment variables.  It was generated from the %q tags of %s and\n",
+		g.opts.tagKey, typeName)
+	fmt.Fprintf(w, "// behaves the same as the Build() function of a cfgbuild.Builder[*%s].\n", typeName)
+	fmt.Fprintf(w, "func Load%s(lookup func(string) (string, bool)) (*%s, error) {\n",
+		exportedName(typeName), typeName)
+	fmt.Fprintf(w, "\tcfg := &%s{}\n", typeName)
+	fmt.Fprintf(w, "\t_, err := cfgbuildLoad%s(cfg, lookup, %q)\n", exportedName(typeName), g.opts.prefix)
+	fmt.Fprintf(w, "\treturn cfg, err\n}\n\n")
+	return nil
+}
+
+func (g *generator) enqueue(typeName string) {
+	if !g.done[typeName] {
+		g.done[typeName] = true
+		g.queue = append(g.queue, typeName)
+	}
+}
+
+// genTypeLoader writes the functions which load the fields of a config type.
+func (g *generator) genTypeLoader(typeName string) error {
+	fields, err := g.structFields(typeName)
+	if err != nil {
+		return err
+	}
+
+	loadName := "cfgbuildLoad" + exportedName(typeName)
+	setName := "cfgbuildSet" + exportedName(typeName) + "Field"
+
+	g.useImport("context")
+	g.useHelper("cfgbuildInit")
+	g.useHelper("cfgbuildValidate")
+
+	body := &bytes.Buffer{}
+	setCases := &bytes.Buffer{}
+
+	// defaults
+	for _, f := range fields {
+		defaultVal, ok := f.attr("default")
+		if !ok || f.envName == ">" {
+			continue
+		}
+		if err := g.genFieldValue(body, setCases, setName, typeName, f, fmt.Sprintf("%q", defaultVal),
+			"error setting default value for %q (%s)"); err != nil {
+			return err
+		}
+	}
+
+	if body.Len() > 0 {
+		fmt.Fprintf(body, "\n")
+	}
+	fmt.Fprintf(body, "\tset := map[string]bool{}\n\n")
+
+	// env vars
+	required := []string{}
+	for _, f := range fields {
+		if _, ok := f.attr("required"); ok {
+			required = append(required, strconv.Quote(f.name))
+		}
+
+		switch f.envName {
+		case "-":
+			continue
+
+		case ">":
+			nestedType, pointer, ok := g.nestedType(f.typ)
+			if !ok {
+				return fmt.Errorf("%s.%s: nested config type %s is not a struct in package %s",
+					typeName, f.name, types.ExprString(f.typ), g.pkgName)
+			}
+			g.enqueue(nestedType)
+			nestedPrefix, _ := f.attr("prefix")
+
+			fmt.Fprintf(body, "\t{\n")
+			fmt.Fprintf(body, "\t\tnested := &%s{}\n", nestedType)
+			// Nested prefixes replace the prefix of the containing config (after the root prefix)
+			fmt.Fprintf(body, "\t\tnestedSet, err := cfgbuildLoad%s(nested, lookup, %q)\n",
+				exportedName(nestedType), g.opts.prefix+nestedPrefix)
+			fmt.Fprintf(body, "\t\tif err != nil {\n\t\t\treturn false, err\n\t\t}\n")
+			fmt.Fprintf(body, "\t\tif nestedSet {\n")
+			if pointer {
+				fmt.Fprintf(body, "\t\t\tcfg.%s = nested\n", f.name)
+			} else {
+				fmt.Fprintf(body, "\t\t\tcfg.%s = *nested\n", f.name)
+			}
+			fmt.Fprintf(body, "\t\t\tset[%q] = true\n\t\t}\n\t}\n\n", f.name)
+			continue
+		}
+
+		names := []string{strconv.Quote(f.envName)}
+		for _, alias := range f.aliases {
+			names = append(names, strconv.Quote(alias))
+		}
+
+		policy := "cfgbuildEmptyAsValue"
+		if _, ok := f.attr("notempty"); ok {
+			policy = "cfgbuildEmptyAsError"
+		} else if _, ok := f.attr("allowEmpty"); ok {
+			policy = "cfgbuildEmptyAsZero"
+		} else if _, ok := f.attr("unsetIfEmpty"); ok {
+			policy = "cfgbuildEmptyAsUnset"
+		}
+		g.useHelper("cfgbuildLookup")
+
+		fmt.Fprintf(body, "\tif s, ok, err := cfgbuildLookup(lookup, prefix, []string{%s}, %s); err != nil {\n",
+			strings.Join(names, ", "), policy)
+		fmt.Fprintf(body, "\t\treturn false, err\n")
+		if policy == "cfgbuildEmptyAsZero" {
+			g.useHelper("cfgbuildZero")
+			fmt.Fprintf(body, "\t} else if ok && s == \"\" {\n")
+			g.useImport("fmt")
+			g.genOneOf(body, "\t\t", f, "s",
+				fmt.Sprintf("\t\t\treturn false, fmt.Errorf(%q, prefix+%q, err)\n", "error reading %q (%s)", f.envName))
+			fmt.Fprintf(body, "\t\tcfgbuildZero(&cfg.%s)\n", f.name)
+			fmt.Fprintf(body, "\t\tset[%q] = true\n", f.name)
+		}
+		fmt.Fprintf(body, "\t} else if ok {\n")
+
+		inner := &bytes.Buffer{}
+		if err := g.genFieldValue(inner, setCases, setName, typeName, f, "s",
+			"error reading %q (%s)"); err != nil {
+			return err
+		}
+		body.Write(inner.Bytes())
+		fmt.Fprintf(body, "\t\tset[%q] = true\n\t}\n\n", f.name)
+	}
+
+	if len(required) > 0 {
+		g.useHelper("cfgbuildCheckRequired")
+		fmt.Fprintf(body, "\tif err := cfgbuildCheckRequired(set, %s); err != nil {\n", strings.Join(required, ", "))
+		fmt.Fprintf(body, "\t\treturn false, err\n\t}\n\n")
+	}
+
+	w := &g.buf
+	fmt.Fprintf(w, "// %s initializes cfg from the values returned by lookup.  The returned bool is true\n", loadName)
+	fmt.Fprintf(w, "// if any fields were set from environment variables.\n")
+	fmt.Fprintf(w, "func %s(cfg *%s, lookup func(string) (string, bool), prefix string) (bool, error) {\n",
+		loadName, typeName)
+	fmt.Fprintf(w, "\tctx := context.Background()\n")
+	fmt.Fprintf(w, "\tif err := cfgbuildInit(ctx, cfg); err != nil {\n\t\treturn false, err\n\t}\n\n")
+	w.Write(body.Bytes())
+	fmt.Fprintf(w, "\treturn len(set) > 0, cfgbuildValidate(ctx, cfg)\n}\n\n")
+
+	if setCases.Len() > 0 {
+		fmt.Fprintf(w, "// %s parses s and sets the named field of cfg.\n", setName)
+		fmt.Fprintf(w, "func %s(cfg *%s, field, s string) error {\n", setName, typeName)
+		fmt.Fprintf(w, "\tswitch field {\n")
+		w.Write(setCases.Bytes())
+		fmt.Fprintf(w, "\t}\n\treturn nil\n}\n\n")
+	}
+	return nil
+}
+
+// genFieldValue writes the statements which set field f to the string expression src.  Errors
+// are wrapped using errFormat (except for unmarshalJSON errors which cfgbuild returns as is).
+func (g *generator) genFieldValue(w, setCases *bytes.Buffer, setName, typeName string, f genField,
+	src, errFormat string) error {
+
+	indent := "\t"
+	if src == "s" {
+		indent = "\t\t"
+	}
+	wrapErr := fmt.Sprintf("%sreturn false, fmt.Errorf(%q, prefix+%q, err)\n", indent+"\t", errFormat, f.envName)
+	g.useImport("fmt")
+
+	g.genOneOf(w, indent, f, src, wrapErr)
+
+	if _, ok := f.attr("unmarshalJSON"); ok {
+		g.useImport("encoding/json")
+		fmt.Fprintf(w, "%sif err := json.Unmarshal([]byte(%s), &cfg.%s); err != nil {\n", indent, src, f.name)
+		fmt.Fprintf(w, "%s\treturn false, err\n%s}\n", indent, indent)
+		return nil
+	}
+
+	if !g.setFields[typeName+"."+f.name] {
+		g.setFields[typeName+"."+f.name] = true
+		code, err := g.setCode(f.typ, f.file, "cfg."+f.name)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %s", typeName, f.name, err)
+		}
+		fmt.Fprintf(setCases, "\tcase %q:\n%s", f.name, code)
+	}
+
+	fmt.Fprintf(w, "%sif err := %s(cfg, %q, %s); err != nil {\n", indent, setName, f.name, src)
+	fmt.Fprintf(w, "%s%s}\n", wrapErr, indent)
+	return nil
+}
+
+// genOneOf writes the statements which check that the string expression src is one of the values
+// allowed by the "oneof" attribute (if field f has one).
+func (g *generator) genOneOf(w *bytes.Buffer, indent string, f genField, src, wrapErr string) {
+	oneOf, ok := f.attr("oneof")
+	if !ok {
+		return
+	}
+	g.useHelper("cfgbuildOneOf")
+	allowed := []string{}
+	for _, a := range strings.Split(oneOf, "|") {
+		allowed = append(allowed, strconv.Quote(a))
+	}
+	fmt.Fprintf(w, "%sif err := cfgbuildOneOf(%s, %s); err != nil {\n", indent, src, strings.Join(allowed, ", "))
+	fmt.Fprintf(w, "%s%s}\n", wrapErr, indent)
+}
+
+// nestedType returns the name of the struct type of a ">" field and whether the field is a
+// pointer.
+func (g *generator) nestedType(expr ast.Expr) (string, bool, bool) {
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		pointer, expr = true, star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false, false
+	}
+	ts, ok := g.specs[ident.Name]
+	if !ok {
+		return "", false, false
+	}
+	_, ok = ts.spec.Type.(*ast.StructType)
+	return ident.Name, pointer, ok
+}
+
+// basicKinds maps the predeclared types supported by the cfgbuild.Builder to their bit sizes.
+var basicKinds = map[string]int{
+	"bool": 0, "string": 0,
+	"int": 0, "int8": 8, "int16": 16, "int32": 32, "int64": 64, "rune": 32,
+	"uint": 0, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "byte": 8,
+	"float32": 32, "float64": 64,
+}
+
+// setCode returns the statements which parse s and assign the result to target.  It follows the
+// same order as cfgbuild.Builder.setFieldValue: specific types, then TextUnmarshaler, and then
+// the kind of the type.
+func (g *generator) setCode(expr ast.Expr, file *ast.File, target string) (string, error) {
+	switch t := expr.(type) {
+
+	case *ast.Ident:
+		if _, ok := basicKinds[t.Name]; ok {
+			return g.basicSetCode(t.Name, t.Name, target), nil
+		}
+		ts, ok := g.specs[t.Name]
+		if !ok {
+			return "", fmt.Errorf("unsupported type %s", t.Name)
+		}
+		if ts.spec.Assign.IsValid() {
+			return g.setCode(ts.spec.Type, ts.file, target)
+		}
+
+		code := g.textUnmarshalerCode(target)
+		if under, ok := ts.spec.Type.(*ast.Ident); ok {
+			if _, ok := basicKinds[under.Name]; ok {
+				return code + g.basicSetCode(under.Name, t.Name, target), nil
+			}
+		}
+		return code + g.unsupportedCode(g.pkgName+"."+t.Name, kindName(ts.spec.Type)), nil
+
+	case *ast.SelectorExpr:
+		path, ok := importPath(file, t)
+		if !ok {
+			return "", fmt.Errorf("unsupported type %s", types.ExprString(t))
+		}
+		switch path + "." + t.Sel.Name {
+		case "time.Duration":
+			g.useHelper("cfgbuildParseDuration")
+			return fmt.Sprintf("\t\td, err := cfgbuildParseDuration(s)\n%s\t\t%s = d\n", errCheck, target), nil
+		case "time.Time":
+			g.useImport("time")
+			return fmt.Sprintf("\t\tt, err := time.Parse(time.RFC3339, s)\n%s\t\t%s = t\n", errCheck, target), nil
+		case "net/url.URL":
+			g.useImport("net/url")
+			return fmt.Sprintf("\t\tu, err := url.Parse(s)\n%s\t\t%s = *u\n", errCheck, target), nil
+		}
+		return g.textUnmarshalerCode(target) + g.unsupportedCode(types.ExprString(t), ""), nil
+
+	case *ast.StarExpr:
+		return g.pointerSetCode(t, file, target)
+
+	case *ast.ArrayType:
+		elt, ok := t.Elt.(*ast.Ident)
+		if t.Len != nil || !ok {
+			return "", fmt.Errorf("unsupported type %s", types.ExprString(t))
+		}
+		switch elt.Name {
+		case "string":
+			g.useHelper("cfgbuildSplit")
+			return fmt.Sprintf("\t\t%s = cfgbuildSplit(s, cfgbuildListSeparator)\n", target), nil
+		case "byte", "uint8":
+			return fmt.Sprintf("\t\t%s = []%s(s)\n", target, elt.Name), nil
+		case "int", "int8", "int16", "int32", "int64", "rune":
+			g.useHelper("cfgbuildParseInts")
+			return fmt.Sprintf("\t\tvals, err := cfgbuildParseInts[%s](s, %s)\n%s\t\t%s = vals\n",
+				elt.Name, g.bitSize(elt.Name), errCheck, target), nil
+		case "uint", "uint16", "uint32", "uint64":
+			g.useHelper("cfgbuildParseUints")
+			return fmt.Sprintf("\t\tvals, err := cfgbuildParseUints[%s](s, %s)\n%s\t\t%s = vals\n",
+				elt.Name, g.bitSize(elt.Name), errCheck, target), nil
+		case "float32", "float64":
+			g.useHelper("cfgbuildParseFloats")
+			return fmt.Sprintf("\t\tvals, err := cfgbuildParseFloats[%s](s, %s)\n%s\t\t%s = vals\n",
+				elt.Name, g.bitSize(elt.Name), errCheck, target), nil
+		}
+		return "", fmt.Errorf("unsupported type %s", types.ExprString(t))
+
+	case *ast.MapType:
+		if types.ExprString(t) != "map[string]string" {
+			return "", fmt.Errorf("unsupported type %s", types.ExprString(t))
+		}
+		g.useHelper("cfgbuildParseMap")
+		return fmt.Sprintf("\t\tmp, err := cfgbuildParseMap(s)\n%s\t\t%s = mp\n", errCheck, target), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", types.ExprString(expr))
+}
+
+const errCheck = "\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n"
+
+// basicSetCode returns the statements which parse s as the predeclared type kind and assign it
+// (converted to typeName) to target.
+func (g *generator) basicSetCode(kind, typeName, target string) string {
+	switch kind {
+	case "string":
+		if typeName == kind {
+			return fmt.Sprintf("\t\t%s = s\n", target)
+		}
+		return fmt.Sprintf("\t\t%s = %s(s)\n", target, typeName)
+	case "bool":
+		g.useHelper("cfgbuildParseBool")
+		return fmt.Sprintf("\t\tb, err := cfgbuildParseBool(s)\n%s\t\t%s = %s(b)\n", errCheck, target, typeName)
+	case "float32", "float64":
+		g.useHelper("cfgbuildParseFloat")
+		return fmt.Sprintf("\t\tf, err := cfgbuildParseFloat(s, %s)\n%s\t\t%s = %s(f)\n",
+			g.bitSize(kind), errCheck, target, typeName)
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		g.useHelper("cfgbuildParseUint")
+		return fmt.Sprintf("\t\tu, err := cfgbuildParseUint(s, %s)\n%s\t\t%s = %s(u)\n",
+			g.bitSize(kind), errCheck, target, typeName)
+	default:
+		g.useHelper("cfgbuildParseInt")
+		return fmt.Sprintf("\t\ti, err := cfgbuildParseInt(s, %s)\n%s\t\t%s = %s(i)\n",
+			g.bitSize(kind), errCheck, target, typeName)
+	}
+}
+
+// pointerSetCode returns the statements for the pointer types supported by the
+// cfgbuild.Builder.
+func (g *generator) pointerSetCode(t *ast.StarExpr, file *ast.File, target string) (string, error) {
+	assign := fmt.Sprintf("%s\t\tp := %%s\n\t\t%s = &p\n", errCheck, target)
+	assignVar := fmt.Sprintf("%s\t\t%s = &%%s\n", errCheck, target)
+
+	switch x := t.X.(type) {
+	case *ast.Ident:
+		bitSize := basicKinds[x.Name]
+		switch x.Name {
+		case "string":
+			return fmt.Sprintf("\t\tp := s\n\t\t%s = &p\n", target), nil
+		case "bool":
+			g.useHelper("cfgbuildParseBool")
+			return "\t\tb, err := cfgbuildParseBool(s)\n" + fmt.Sprintf(assignVar, "b"), nil
+		case "int", "int8", "int16", "int32", "int64":
+			g.useImport("strconv")
+			if bitSize == 0 {
+				bitSize = 64
+			}
+			return fmt.Sprintf("\t\ti, err := strconv.ParseInt(s, 10, %d)\n", bitSize) +
+				fmt.Sprintf(assign, x.Name+"(i)"), nil
+		case "uint", "uint8", "uint16", "uint32", "uint64":
+			g.useImport("strconv")
+			if bitSize == 0 {
+				bitSize = 64
+			}
+			return fmt.Sprintf("\t\tu, err := strconv.ParseUint(s, 10, %d)\n", bitSize) +
+				fmt.Sprintf(assign, x.Name+"(u)"), nil
+		case "float32", "float64":
+			g.useImport("strconv")
+			return fmt.Sprintf("\t\tf, err := strconv.ParseFloat(s, %d)\n", bitSize) +
+				fmt.Sprintf(assign, x.Name+"(f)"), nil
+		}
+
+	case *ast.SelectorExpr:
+		path, _ := importPath(file, x)
+		switch path + "." + x.Sel.Name {
+		case "time.Duration":
+			g.useHelper("cfgbuildParseDuration")
+			return "\t\td, err := cfgbuildParseDuration(s)\n" + fmt.Sprintf(assignVar, "d"), nil
+		case "net/url.URL":
+			g.useImport("net/url")
+			return fmt.Sprintf("\t\tu, err := url.Parse(s)\n%s\t\t%s = u\n", errCheck, target), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", types.ExprString(t))
+}
+
+// textUnmarshalerCode returns the statements which use UnmarshalText if the target implements
+// encoding.TextUnmarshaler.
+func (g *generator) textUnmarshalerCode(target string) string {
+	g.useImport("encoding")
+	return fmt.Sprintf("\t\tif u, ok := any(&%s).(encoding.TextUnmarshaler); ok {\n"+
+		"\t\t\treturn u.UnmarshalText([]byte(s))\n\t\t}\n", target)
+}
+
+// unsupportedCode returns the statement which reports a type that isn't supported at runtime.
+func (g *generator) unsupportedCode(typeName, kind string) string {
+	g.useImport("fmt")
+	if kind == "" {
+		return fmt.Sprintf("\t\treturn fmt.Errorf(\"unsupported type %%q\", %q)\n", typeName)
+	}
+	return fmt.Sprintf("\t\treturn fmt.Errorf(\"unsupported type/kind %%q\", %q)\n", typeName+"/"+kind)
+}
+
+// bitSize returns the bit size expression for a predeclared numeric type.
+func (g *generator) bitSize(kind string) string {
+	if size := basicKinds[kind]; size != 0 {
+		return strconv.Itoa(size)
+	}
+	g.useImport("strconv")
+	return "strconv.IntSize"
+}
+
+// kindName returns the reflect.Kind name for a type expression.
+func kindName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.MapType:
+		return "map"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "slice"
+		}
+		return "array"
+	case *ast.StarExpr:
+		return "ptr"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.ChanType:
+		return "chan"
+	}
+	return types.ExprString(expr)
+}
+
+// importPath returns the import path of the package referenced by a selector expression.
+func importPath(file *ast.File, sel *ast.SelectorExpr) (string, bool) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == x.Name {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// exportedName returns the name with the first letter in upper case.
+func exportedName(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func (g *generator) useImport(path string) {
+	g.imports[path] = true
+}
+
+func (g *generator) useHelper(name string) {
+	if g.helpers[name] {
+		return
+	}
+	g.helpers[name] = true
+	h := helpers[name]
+	for _, path := range h.imports {
+		g.useImport(path)
+	}
+	for _, dep := range h.deps {
+		g.useHelper(dep)
+	}
+}
+
+// genHelpers writes the helper functions used by the generated code.
+func (g *generator) genHelpers() {
+	names := []string{}
+	for name := range g.helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if g.helpers["cfgbuildSplit"] {
+		fmt.Fprintf(&g.buf, "const (\n\tcfgbuildListSeparator     = %q\n\tcfgbuildKeyValueSeparator = %q\n)\n\n",
+			g.opts.listSeparator, g.opts.keyValueSeparator)
+	}
+	for _, name := range names {
+		if code := helpers[name].code; code != "" && g.helpers[name] {
+			g.buf.WriteString(strings.TrimPrefix(code, "\n"))
+			g.buf.WriteString("\n")
+		}
+	}
+}
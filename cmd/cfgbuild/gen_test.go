@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureOptions(dir string) genOptions {
+	return genOptions{
+		dir:               dir,
+		types:             []string{"Config"},
+		output:            filepath.Join(dir, "config_cfgbuild.go"),
+		prefix:            "APP_",
+		tagKey:            "envvar",
+		listSeparator:     ",",
+		keyValueSeparator: ":",
+	}
+}
+
+// TestGenerateFixture checks that the committed generated code for the fixture package is up to
+// date (run "go generate ./..." if it fails).
+func TestGenerateFixture(t *testing.T) {
+	opts := fixtureOptions(filepath.Join("internal", "fixture"))
+
+	want, err := os.ReadFile(opts.output)
+	assert.NoError(t, err)
+
+	got, err := generate(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+// TestGenerateOutput checks that relative output names are in the package directory while
+// absolute output names are used as is.
+func TestGenerateOutput(t *testing.T) {
+	dir := filepath.Join("internal", "fixture")
+	want, err := os.ReadFile(filepath.Join(dir, "config_cfgbuild.go"))
+	assert.NoError(t, err)
+
+	output := filepath.Join(t.TempDir(), "gen_out.go")
+	err = runGen([]string{"-type", "Config", "-prefix", "APP_", "-output", output, dir})
+	assert.NoError(t, err)
+	got, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+
+	relative := filepath.Join(dir, "gen_out_test.txt")
+	t.Cleanup(func() { os.Remove(relative) })
+	err = runGen([]string{"-type", "Config", "-prefix", "APP_", "-output", "gen_out_test.txt", dir})
+	assert.NoError(t, err)
+	assert.FileExists(t, relative)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tsts := []struct {
+		src string
+		err string
+	}{
+		{"type Config struct {\n\tPort int `envvar:\"PORT,requird\"`\n}",
+			`Config.Port: tag value contains non-existent attribute "requird"`},
+		{"type Config struct {\n\tKey []byte `envvar:\"KEY,parser=hex\"`\n}",
+			`Config.Key: the "parser" attribute is not supported by the generator`},
+		{"type Config struct {\n\tport int `envvar:\"PORT\"`\n}",
+			`Config.port: non-public fields may not have the tag set`},
+		{"type Config struct {\n\tPort int `envvar:\"PORT,prefix=X_\"`\n}",
+			`Config.Port: the "prefix" attribute is only allowed on ">" nested config fields`},
+		{"type Config struct {\n\tPort int `envvar:\"-,required\"`\n}",
+			`Config.Port: the "required" attribute is not allowed on "-" fields`},
+		{"type Config struct {\n\tCh chan int `envvar:\"CH\"`\n}",
+			`Config.Ch: unsupported type chan int`},
+		{"type Config struct {\n\tP *Config `envvar:\"P\"`\n}",
+			`Config.P: unsupported type *Config`},
+		{"type Config struct {\n\tN []int `envvar:\">\"`\n}",
+			`Config.N: nested config type []int is not a struct in package fixture`},
+		{"type Other int",
+			`type "Config" not found`},
+	}
+
+	for i, tst := range tsts {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "config.go"), []byte("package fixture\n\n"+tst.src+"\n"), 0o644)
+		assert.NoError(t, err, i)
+
+		_, err = generate(fixtureOptions(dir))
+		assert.EqualError(t, err, tst.err, i)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+// A helper is a function (or set of declarations) which is added to the generated code when it
+// is used.  The helpers reproduce the parsing done by cfgbuild.Builder so that the generated code
+// doesn't need to import cfgbuild (or use reflection).
+type helper struct {
+	imports []string
+	deps    []string
+	code    string
+}
+
+var helpers = map[string]helper{
+	"cfgbuildInit": {
+		imports: []string{"context"},
+		code: `
+// cfgbuildInit runs the CfgBuildInitContext() or CfgBuildInit() function of cfg (if it exists).
+func cfgbuildInit(ctx context.Context, cfg any) error {
+	if initter, ok := cfg.(interface{ CfgBuildInitContext(context.Context) error }); ok {
+		return initter.CfgBuildInitContext(ctx)
+	}
+	if initter, ok := cfg.(interface{ CfgBuildInit() error }); ok {
+		return initter.CfgBuildInit()
+	}
+	return nil
+}
+`,
+	},
+
+	"cfgbuildValidate": {
+		imports: []string{"context"},
+		code: `
+// cfgbuildValidate runs the CfgBuildValidateContext() or CfgBuildValidate() function of cfg (if
+// it exists).
+func cfgbuildValidate(ctx context.Context, cfg any) error {
+	if validator, ok := cfg.(interface{ CfgBuildValidateContext(context.Context) error }); ok {
+		return validator.CfgBuildValidateContext(ctx)
+	}
+	if validator, ok := cfg.(interface{ CfgBuildValidate() error }); ok {
+		return validator.CfgBuildValidate()
+	}
+	return nil
+}
+`,
+	},
+
+	"cfgbuildLookup": {
+		imports: []string{"fmt"},
+		code: `
+// The empty policies match cfgbuild.EmptyPolicy.
+const (
+	cfgbuildEmptyAsValue = iota
+	cfgbuildEmptyAsUnset
+	cfgbuildEmptyAsError
+	cfgbuildEmptyAsZero
+)
+
+// cfgbuildLookup returns the value of the first of the names (the env var name followed by any
+// deprecated aliases) which is set.  An error is returned if more than one of the names is set
+// and the values differ.
+func cfgbuildLookup(lookup func(string) (string, bool), prefix string, names []string,
+	policy int) (string, bool, error) {
+	var val, used string
+	found := false
+
+	for _, name := range names {
+		v, ok := lookup(prefix + name)
+		if !ok {
+			continue
+		}
+		if v == "" {
+			switch policy {
+			case cfgbuildEmptyAsUnset:
+				continue
+			case cfgbuildEmptyAsError:
+				return "", false, fmt.Errorf("error reading %q (value may not be empty)", prefix+name)
+			}
+		}
+
+		if !found {
+			val, used, found = v, prefix+name, true
+		} else if v != val {
+			return "", false, fmt.Errorf("error reading %q (conflicting values set for %q and %q)",
+				prefix+names[0], used, prefix+name)
+		}
+	}
+	return val, found, nil
+}
+`,
+	},
+
+	"cfgbuildCheckRequired": {
+		imports: []string{"fmt", "strings"},
+		code: `
+// cfgbuildCheckRequired returns an error if any of the required fields were not set.
+func cfgbuildCheckRequired(set map[string]bool, fields ...string) error {
+	missing := []string{}
+	for _, field := range fields {
+		if !set[field] {
+			missing = append(missing, field)
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("missing required var %q", missing[0])
+	default:
+		return fmt.Errorf("missing required vars: %s", strings.Join(missing, ","))
+	}
+}
+`,
+	},
+
+	"cfgbuildOneOf": {
+		imports: []string{"fmt", "strings"},
+		code: `
+// cfgbuildOneOf returns an error if val is not one of the allowed values.
+func cfgbuildOneOf(val string, allowed ...string) error {
+	for _, a := range allowed {
+		if val == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("value %q is not one of %s", val, strings.Join(allowed, ", "))
+}
+`,
+	},
+
+	"cfgbuildZero": {
+		code: `
+// cfgbuildZero sets the value pointed to by p to the zero value for its type.
+func cfgbuildZero[T any](p *T) {
+	var zero T
+	*p = zero
+}
+`,
+	},
+
+	"cfgbuildParseBool": {
+		imports: []string{"fmt", "strings"},
+		code: `
+func cfgbuildParseBool(s string) (bool, error) {
+	switch strings.ToUpper(s) {
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	}
+	return false, fmt.Errorf("string %q is not a valid boolean value", s)
+}
+`,
+	},
+
+	"cfgbuildParseInt": {
+		imports: []string{"errors", "strconv"},
+		code: `
+func cfgbuildParseInt(s string, bitSize int) (int64, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize < 64 && (i < -1<<(bitSize-1) || i >= 1<<(bitSize-1)) {
+		return 0, errors.New("overflow error")
+	}
+	return i, nil
+}
+`,
+	},
+
+	"cfgbuildParseUint": {
+		imports: []string{"errors", "strconv"},
+		code: `
+func cfgbuildParseUint(s string, bitSize int) (uint64, error) {
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize < 64 && u >= 1<<bitSize {
+		return 0, errors.New("overflow error")
+	}
+	return u, nil
+}
+`,
+	},
+
+	"cfgbuildParseFloat": {
+		imports: []string{"errors", "math", "strconv"},
+		code: `
+func cfgbuildParseFloat(s string, bitSize int) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, errors.New("overflow error")
+	}
+	return f, nil
+}
+`,
+	},
+
+	"cfgbuildParseDuration": {
+		imports: []string{"strconv", "time"},
+		code: `
+// cfgbuildParseDuration parses a number of nanoseconds or a duration string (ie "3s").
+func cfgbuildParseDuration(s string) (time.Duration, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.ParseDuration(s)
+	}
+	return time.Duration(i), nil
+}
+`,
+	},
+
+	"cfgbuildSplit": {
+		imports: []string{"strings"},
+		code: `
+func cfgbuildSplit(s, sep string) []string {
+	vals := strings.Split(s, sep)
+	out := []string{}
+	for _, v := range vals {
+		out = append(out, strings.TrimSpace(v))
+	}
+	return out
+}
+`,
+	},
+
+	"cfgbuildParseInts": {
+		imports: []string{"strconv"},
+		deps:    []string{"cfgbuildSplit"},
+		code: `
+func cfgbuildParseInts[T int | int8 | int16 | int32 | int64](s string, bitSize int) ([]T, error) {
+	vals := []T{}
+	for _, v := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		i, err := strconv.ParseInt(v, 10, bitSize)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, T(i))
+	}
+	return vals, nil
+}
+`,
+	},
+
+	"cfgbuildParseUints": {
+		imports: []string{"strconv"},
+		deps:    []string{"cfgbuildSplit"},
+		code: `
+func cfgbuildParseUints[T uint | uint8 | uint16 | uint32 | uint64](s string, bitSize int) ([]T, error) {
+	vals := []T{}
+	for _, v := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		u, err := strconv.ParseUint(v, 10, bitSize)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, T(u))
+	}
+	return vals, nil
+}
+`,
+	},
+
+	"cfgbuildParseFloats": {
+		imports: []string{"strconv"},
+		deps:    []string{"cfgbuildSplit"},
+		code: `
+func cfgbuildParseFloats[T float32 | float64](s string, bitSize int) ([]T, error) {
+	vals := []T{}
+	for _, v := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		f, err := strconv.ParseFloat(v, bitSize)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, T(f))
+	}
+	return vals, nil
+}
+`,
+	},
+
+	"cfgbuildParseMap": {
+		imports: []string{"fmt"},
+		deps:    []string{"cfgbuildSplit"},
+		code: `
+func cfgbuildParseMap(s string) (map[string]string, error) {
+	mp := make(map[string]string)
+	for _, pair := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		kv := cfgbuildSplit(pair, cfgbuildKeyValueSeparator)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("key/value pair must contain exactly one %q separator",
+				cfgbuildKeyValueSeparator)
+		}
+		mp[kv[0]] = kv[1]
+	}
+	return mp, nil
+}
+`,
+	},
+}
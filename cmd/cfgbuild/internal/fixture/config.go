@@ -0,0 +1,126 @@
+// Package fixture contains configs used to test that the code generated by cfgbuild gen behaves
+// the same as the cfgbuild.Builder.
+package fixture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//go:generate go run github.com/NathanBak/cfgbuild/cmd/cfgbuild gen -type Config -prefix APP_
+
+// Config has a field for each kind of type supported by cfgbuild gen.
+type Config struct {
+	Name       string            `envvar:"NAME,default=fixture"`
+	Port       int               `envvar:"PORT,required"`
+	Small      int8              `envvar:"SMALL"`
+	Big        uint64            `envvar:"BIG"`
+	Medium     uint16            `envvar:"MEDIUM,unsetIfEmpty,default=7"`
+	Ratio      float32           `envvar:"RATIO,default=0.5"`
+	Enabled    bool              `envvar:"ENABLED"`
+	Timeout    time.Duration     `envvar:"TIMEOUT,default=30s"`
+	Start      time.Time         `envvar:"START"`
+	Endpoint   url.URL           `envvar:"ENDPOINT"`
+	Level      string            `envvar:"LEVEL,default=info,oneof=debug|info|warn|error"`
+	Hosts      []string          `envvar:"HOSTS"`
+	Counts     []int             `envvar:"COUNTS"`
+	Weights    []float64         `envvar:"WEIGHTS"`
+	Masks      []uint16          `envvar:"MASKS"`
+	Key        []byte            `envvar:"KEY,secret"`
+	Labels     map[string]string `envvar:"LABELS"`
+	Retries    *int              `envvar:"RETRIES"`
+	Owner      *string           `envvar:"OWNER"`
+	Verbose    *bool             `envvar:"VERBOSE"`
+	Interval   *time.Duration    `envvar:"INTERVAL"`
+	Callback   *url.URL          `envvar:"CALLBACK"`
+	Color      Color             `envvar:"COLOR,default=red"`
+	Priority   Priority          `envvar:"PRIORITY"`
+	Addr       net.IP            `envvar:"ADDR"`
+	Host       string            `envvar:"HOST|HOSTNAME|SERVER"`
+	Token      string            `envvar:"TOKEN,notempty"`
+	Note       string            `envvar:"NOTE,allowEmpty,default=none"`
+	Tier       string            `envvar:"TIER,allowEmpty,oneof=gold|silver"`
+	Limits     Limits            `envvar:"LIMITS,unmarshalJSON"`
+	Computed   string            `envvar:"-,default=computed"`
+	DB         DBConfig          `envvar:">,prefix=DB_"`
+	Cache      *CacheConfig      `envvar:">,prefix=CACHE_"`
+	Fallback   DBConfig          `envvar:">"`
+	InitCalled bool
+}
+
+func (cfg *Config) CfgBuildInit() error {
+	cfg.InitCalled = true
+	return nil
+}
+
+func (cfg *Config) CfgBuildValidate() error {
+	if cfg.Port == 13 {
+		return errors.New("unlucky port")
+	}
+	return nil
+}
+
+// DBConfig is a nested config with a context validate function.
+type DBConfig struct {
+	User     string `envvar:"USER"`
+	Password string `envvar:"PASSWORD,secret"`
+	PoolSize int    `envvar:"POOL_SIZE,default=10"`
+}
+
+func (cfg *DBConfig) CfgBuildValidateContext(ctx context.Context) error {
+	if cfg.PoolSize < 1 {
+		return fmt.Errorf("pool size must be positive (got %d)", cfg.PoolSize)
+	}
+	return nil
+}
+
+// CacheConfig is a nested config which is a pointer field and has a context init function.
+type CacheConfig struct {
+	Size int           `envvar:"SIZE"`
+	TTL  time.Duration `envvar:"TTL,default=1m"`
+	Mode string        `envvar:"MODE"`
+	// Backup is nested two levels deep (and so reads APP_BACKUP_* rather than APP_CACHE_BACKUP_*)
+	Backup *DBConfig `envvar:">,prefix=BACKUP_"`
+}
+
+func (cfg *CacheConfig) CfgBuildInitContext(ctx context.Context) error {
+	cfg.Mode = "lru"
+	return nil
+}
+
+// Color implements encoding.TextUnmarshaler.
+type Color int
+
+const (
+	Red Color = iota + 1
+	Green
+	Blue
+)
+
+func (c *Color) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "red":
+		*c = Red
+	case "green":
+		*c = Green
+	case "blue":
+		*c = Blue
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+// Priority is parsed as its underlying type.
+type Priority uint8
+
+// Limits is read from JSON.
+type Limits struct {
+	Max int `json:"max"`
+	Min int `json:"min"`
+}
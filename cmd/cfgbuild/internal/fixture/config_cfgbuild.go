@@ -0,0 +1,896 @@
+// Code generated by cfgbuild gen; DO NOT EDIT.
+
+package fixture
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadConfig creates and initializes a new Config using lookup (such as os.LookupEnv) to
+// read the environment variables.  It was generated from the "envvar" tags of Config and
+// behaves the same as the Build() function of a cfgbuild.Builder[*Config].
+func LoadConfig(lookup func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{}
+	_, err := cfgbuildLoadConfig(cfg, lookup, "APP_")
+	return cfg, err
+}
+
+// cfgbuildLoadConfig initializes cfg from the values returned by lookup.  The returned bool is true
+// if any fields were set from environment variables.
+func cfgbuildLoadConfig(cfg *Config, lookup func(string) (string, bool), prefix string) (bool, error) {
+	ctx := context.Background()
+	if err := cfgbuildInit(ctx, cfg); err != nil {
+		return false, err
+	}
+
+	if err := cfgbuildSetConfigField(cfg, "Name", "fixture"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"NAME", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Medium", "7"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"MEDIUM", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Ratio", "0.5"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"RATIO", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Timeout", "30s"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"TIMEOUT", err)
+	}
+	if err := cfgbuildOneOf("info", "debug", "info", "warn", "error"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"LEVEL", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Level", "info"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"LEVEL", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Color", "red"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"COLOR", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Note", "none"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"NOTE", err)
+	}
+	if err := cfgbuildSetConfigField(cfg, "Computed", "computed"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"-", err)
+	}
+
+	set := map[string]bool{}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"NAME"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Name", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"NAME", err)
+		}
+		set["Name"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"PORT"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Port", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"PORT", err)
+		}
+		set["Port"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"SMALL"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Small", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"SMALL", err)
+		}
+		set["Small"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"BIG"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Big", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"BIG", err)
+		}
+		set["Big"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"MEDIUM"}, cfgbuildEmptyAsUnset); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Medium", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"MEDIUM", err)
+		}
+		set["Medium"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"RATIO"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Ratio", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"RATIO", err)
+		}
+		set["Ratio"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"ENABLED"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Enabled", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"ENABLED", err)
+		}
+		set["Enabled"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"TIMEOUT"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Timeout", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"TIMEOUT", err)
+		}
+		set["Timeout"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"START"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Start", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"START", err)
+		}
+		set["Start"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"ENDPOINT"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Endpoint", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"ENDPOINT", err)
+		}
+		set["Endpoint"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"LEVEL"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildOneOf(s, "debug", "info", "warn", "error"); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"LEVEL", err)
+		}
+		if err := cfgbuildSetConfigField(cfg, "Level", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"LEVEL", err)
+		}
+		set["Level"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"HOSTS"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Hosts", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"HOSTS", err)
+		}
+		set["Hosts"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"COUNTS"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Counts", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"COUNTS", err)
+		}
+		set["Counts"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"WEIGHTS"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Weights", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"WEIGHTS", err)
+		}
+		set["Weights"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"MASKS"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Masks", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"MASKS", err)
+		}
+		set["Masks"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"KEY"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Key", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"KEY", err)
+		}
+		set["Key"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"LABELS"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Labels", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"LABELS", err)
+		}
+		set["Labels"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"RETRIES"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Retries", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"RETRIES", err)
+		}
+		set["Retries"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"OWNER"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Owner", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"OWNER", err)
+		}
+		set["Owner"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"VERBOSE"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Verbose", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"VERBOSE", err)
+		}
+		set["Verbose"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"INTERVAL"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Interval", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"INTERVAL", err)
+		}
+		set["Interval"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"CALLBACK"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Callback", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"CALLBACK", err)
+		}
+		set["Callback"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"COLOR"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Color", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"COLOR", err)
+		}
+		set["Color"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"PRIORITY"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Priority", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"PRIORITY", err)
+		}
+		set["Priority"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"ADDR"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Addr", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"ADDR", err)
+		}
+		set["Addr"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"HOST", "HOSTNAME", "SERVER"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Host", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"HOST", err)
+		}
+		set["Host"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"TOKEN"}, cfgbuildEmptyAsError); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Token", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"TOKEN", err)
+		}
+		set["Token"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"NOTE"}, cfgbuildEmptyAsZero); err != nil {
+		return false, err
+	} else if ok && s == "" {
+		cfgbuildZero(&cfg.Note)
+		set["Note"] = true
+	} else if ok {
+		if err := cfgbuildSetConfigField(cfg, "Note", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"NOTE", err)
+		}
+		set["Note"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"TIER"}, cfgbuildEmptyAsZero); err != nil {
+		return false, err
+	} else if ok && s == "" {
+		if err := cfgbuildOneOf(s, "gold", "silver"); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"TIER", err)
+		}
+		cfgbuildZero(&cfg.Tier)
+		set["Tier"] = true
+	} else if ok {
+		if err := cfgbuildOneOf(s, "gold", "silver"); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"TIER", err)
+		}
+		if err := cfgbuildSetConfigField(cfg, "Tier", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"TIER", err)
+		}
+		set["Tier"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"LIMITS"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := json.Unmarshal([]byte(s), &cfg.Limits); err != nil {
+			return false, err
+		}
+		set["Limits"] = true
+	}
+
+	{
+		nested := &DBConfig{}
+		nestedSet, err := cfgbuildLoadDBConfig(nested, lookup, "APP_DB_")
+		if err != nil {
+			return false, err
+		}
+		if nestedSet {
+			cfg.DB = *nested
+			set["DB"] = true
+		}
+	}
+
+	{
+		nested := &CacheConfig{}
+		nestedSet, err := cfgbuildLoadCacheConfig(nested, lookup, "APP_CACHE_")
+		if err != nil {
+			return false, err
+		}
+		if nestedSet {
+			cfg.Cache = nested
+			set["Cache"] = true
+		}
+	}
+
+	{
+		nested := &DBConfig{}
+		nestedSet, err := cfgbuildLoadDBConfig(nested, lookup, "APP_")
+		if err != nil {
+			return false, err
+		}
+		if nestedSet {
+			cfg.Fallback = *nested
+			set["Fallback"] = true
+		}
+	}
+
+	if err := cfgbuildCheckRequired(set, "Port"); err != nil {
+		return false, err
+	}
+
+	return len(set) > 0, cfgbuildValidate(ctx, cfg)
+}
+
+// cfgbuildSetConfigField parses s and sets the named field of cfg.
+func cfgbuildSetConfigField(cfg *Config, field, s string) error {
+	switch field {
+	case "Name":
+		cfg.Name = s
+	case "Medium":
+		u, err := cfgbuildParseUint(s, 16)
+		if err != nil {
+			return err
+		}
+		cfg.Medium = uint16(u)
+	case "Ratio":
+		f, err := cfgbuildParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		cfg.Ratio = float32(f)
+	case "Timeout":
+		d, err := cfgbuildParseDuration(s)
+		if err != nil {
+			return err
+		}
+		cfg.Timeout = d
+	case "Level":
+		cfg.Level = s
+	case "Color":
+		if u, ok := any(&cfg.Color).(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+		i, err := cfgbuildParseInt(s, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		cfg.Color = Color(i)
+	case "Note":
+		cfg.Note = s
+	case "Computed":
+		cfg.Computed = s
+	case "Port":
+		i, err := cfgbuildParseInt(s, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		cfg.Port = int(i)
+	case "Small":
+		i, err := cfgbuildParseInt(s, 8)
+		if err != nil {
+			return err
+		}
+		cfg.Small = int8(i)
+	case "Big":
+		u, err := cfgbuildParseUint(s, 64)
+		if err != nil {
+			return err
+		}
+		cfg.Big = uint64(u)
+	case "Enabled":
+		b, err := cfgbuildParseBool(s)
+		if err != nil {
+			return err
+		}
+		cfg.Enabled = bool(b)
+	case "Start":
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		cfg.Start = t
+	case "Endpoint":
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		cfg.Endpoint = *u
+	case "Hosts":
+		cfg.Hosts = cfgbuildSplit(s, cfgbuildListSeparator)
+	case "Counts":
+		vals, err := cfgbuildParseInts[int](s, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		cfg.Counts = vals
+	case "Weights":
+		vals, err := cfgbuildParseFloats[float64](s, 64)
+		if err != nil {
+			return err
+		}
+		cfg.Weights = vals
+	case "Masks":
+		vals, err := cfgbuildParseUints[uint16](s, 16)
+		if err != nil {
+			return err
+		}
+		cfg.Masks = vals
+	case "Key":
+		cfg.Key = []byte(s)
+	case "Labels":
+		mp, err := cfgbuildParseMap(s)
+		if err != nil {
+			return err
+		}
+		cfg.Labels = mp
+	case "Retries":
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		p := int(i)
+		cfg.Retries = &p
+	case "Owner":
+		p := s
+		cfg.Owner = &p
+	case "Verbose":
+		b, err := cfgbuildParseBool(s)
+		if err != nil {
+			return err
+		}
+		cfg.Verbose = &b
+	case "Interval":
+		d, err := cfgbuildParseDuration(s)
+		if err != nil {
+			return err
+		}
+		cfg.Interval = &d
+	case "Callback":
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		cfg.Callback = u
+	case "Priority":
+		if u, ok := any(&cfg.Priority).(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+		u, err := cfgbuildParseUint(s, 8)
+		if err != nil {
+			return err
+		}
+		cfg.Priority = Priority(u)
+	case "Addr":
+		if u, ok := any(&cfg.Addr).(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+		return fmt.Errorf("unsupported type %q", "net.IP")
+	case "Host":
+		cfg.Host = s
+	case "Token":
+		cfg.Token = s
+	case "Tier":
+		cfg.Tier = s
+	}
+	return nil
+}
+
+// cfgbuildLoadDBConfig initializes cfg from the values returned by lookup.  The returned bool is true
+// if any fields were set from environment variables.
+func cfgbuildLoadDBConfig(cfg *DBConfig, lookup func(string) (string, bool), prefix string) (bool, error) {
+	ctx := context.Background()
+	if err := cfgbuildInit(ctx, cfg); err != nil {
+		return false, err
+	}
+
+	if err := cfgbuildSetDBConfigField(cfg, "PoolSize", "10"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"POOL_SIZE", err)
+	}
+
+	set := map[string]bool{}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"USER"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetDBConfigField(cfg, "User", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"USER", err)
+		}
+		set["User"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"PASSWORD"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetDBConfigField(cfg, "Password", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"PASSWORD", err)
+		}
+		set["Password"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"POOL_SIZE"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetDBConfigField(cfg, "PoolSize", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"POOL_SIZE", err)
+		}
+		set["PoolSize"] = true
+	}
+
+	return len(set) > 0, cfgbuildValidate(ctx, cfg)
+}
+
+// cfgbuildSetDBConfigField parses s and sets the named field of cfg.
+func cfgbuildSetDBConfigField(cfg *DBConfig, field, s string) error {
+	switch field {
+	case "PoolSize":
+		i, err := cfgbuildParseInt(s, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		cfg.PoolSize = int(i)
+	case "User":
+		cfg.User = s
+	case "Password":
+		cfg.Password = s
+	}
+	return nil
+}
+
+// cfgbuildLoadCacheConfig initializes cfg from the values returned by lookup.  The returned bool is true
+// if any fields were set from environment variables.
+func cfgbuildLoadCacheConfig(cfg *CacheConfig, lookup func(string) (string, bool), prefix string) (bool, error) {
+	ctx := context.Background()
+	if err := cfgbuildInit(ctx, cfg); err != nil {
+		return false, err
+	}
+
+	if err := cfgbuildSetCacheConfigField(cfg, "TTL", "1m"); err != nil {
+		return false, fmt.Errorf("error setting default value for %q (%s)", prefix+"TTL", err)
+	}
+
+	set := map[string]bool{}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"SIZE"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetCacheConfigField(cfg, "Size", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"SIZE", err)
+		}
+		set["Size"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"TTL"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetCacheConfigField(cfg, "TTL", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"TTL", err)
+		}
+		set["TTL"] = true
+	}
+
+	if s, ok, err := cfgbuildLookup(lookup, prefix, []string{"MODE"}, cfgbuildEmptyAsValue); err != nil {
+		return false, err
+	} else if ok {
+		if err := cfgbuildSetCacheConfigField(cfg, "Mode", s); err != nil {
+			return false, fmt.Errorf("error reading %q (%s)", prefix+"MODE", err)
+		}
+		set["Mode"] = true
+	}
+
+	{
+		nested := &DBConfig{}
+		nestedSet, err := cfgbuildLoadDBConfig(nested, lookup, "APP_BACKUP_")
+		if err != nil {
+			return false, err
+		}
+		if nestedSet {
+			cfg.Backup = nested
+			set["Backup"] = true
+		}
+	}
+
+	return len(set) > 0, cfgbuildValidate(ctx, cfg)
+}
+
+// cfgbuildSetCacheConfigField parses s and sets the named field of cfg.
+func cfgbuildSetCacheConfigField(cfg *CacheConfig, field, s string) error {
+	switch field {
+	case "TTL":
+		d, err := cfgbuildParseDuration(s)
+		if err != nil {
+			return err
+		}
+		cfg.TTL = d
+	case "Size":
+		i, err := cfgbuildParseInt(s, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		cfg.Size = int(i)
+	case "Mode":
+		cfg.Mode = s
+	}
+	return nil
+}
+
+const (
+	cfgbuildListSeparator     = ","
+	cfgbuildKeyValueSeparator = ":"
+)
+
+// cfgbuildCheckRequired returns an error if any of the required fields were not set.
+func cfgbuildCheckRequired(set map[string]bool, fields ...string) error {
+	missing := []string{}
+	for _, field := range fields {
+		if !set[field] {
+			missing = append(missing, field)
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("missing required var %q", missing[0])
+	default:
+		return fmt.Errorf("missing required vars: %s", strings.Join(missing, ","))
+	}
+}
+
+// cfgbuildInit runs the CfgBuildInitContext() or CfgBuildInit() function of cfg (if it exists).
+func cfgbuildInit(ctx context.Context, cfg any) error {
+	if initter, ok := cfg.(interface{ CfgBuildInitContext(context.Context) error }); ok {
+		return initter.CfgBuildInitContext(ctx)
+	}
+	if initter, ok := cfg.(interface{ CfgBuildInit() error }); ok {
+		return initter.CfgBuildInit()
+	}
+	return nil
+}
+
+// The empty policies match cfgbuild.EmptyPolicy.
+const (
+	cfgbuildEmptyAsValue = iota
+	cfgbuildEmptyAsUnset
+	cfgbuildEmptyAsError
+	cfgbuildEmptyAsZero
+)
+
+// cfgbuildLookup returns the value of the first of the names (the env var name followed by any
+// deprecated aliases) which is set.  An error is returned if more than one of the names is set
+// and the values differ.
+func cfgbuildLookup(lookup func(string) (string, bool), prefix string, names []string,
+	policy int) (string, bool, error) {
+	var val, used string
+	found := false
+
+	for _, name := range names {
+		v, ok := lookup(prefix + name)
+		if !ok {
+			continue
+		}
+		if v == "" {
+			switch policy {
+			case cfgbuildEmptyAsUnset:
+				continue
+			case cfgbuildEmptyAsError:
+				return "", false, fmt.Errorf("error reading %q (value may not be empty)", prefix+name)
+			}
+		}
+
+		if !found {
+			val, used, found = v, prefix+name, true
+		} else if v != val {
+			return "", false, fmt.Errorf("error reading %q (conflicting values set for %q and %q)",
+				prefix+names[0], used, prefix+name)
+		}
+	}
+	return val, found, nil
+}
+
+// cfgbuildOneOf returns an error if val is not one of the allowed values.
+func cfgbuildOneOf(val string, allowed ...string) error {
+	for _, a := range allowed {
+		if val == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("value %q is not one of %s", val, strings.Join(allowed, ", "))
+}
+
+func cfgbuildParseBool(s string) (bool, error) {
+	switch strings.ToUpper(s) {
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	}
+	return false, fmt.Errorf("string %q is not a valid boolean value", s)
+}
+
+// cfgbuildParseDuration parses a number of nanoseconds or a duration string (ie "3s").
+func cfgbuildParseDuration(s string) (time.Duration, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.ParseDuration(s)
+	}
+	return time.Duration(i), nil
+}
+
+func cfgbuildParseFloat(s string, bitSize int) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, errors.New("overflow error")
+	}
+	return f, nil
+}
+
+func cfgbuildParseFloats[T float32 | float64](s string, bitSize int) ([]T, error) {
+	vals := []T{}
+	for _, v := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		f, err := strconv.ParseFloat(v, bitSize)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, T(f))
+	}
+	return vals, nil
+}
+
+func cfgbuildParseInt(s string, bitSize int) (int64, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize < 64 && (i < -1<<(bitSize-1) || i >= 1<<(bitSize-1)) {
+		return 0, errors.New("overflow error")
+	}
+	return i, nil
+}
+
+func cfgbuildParseInts[T int | int8 | int16 | int32 | int64](s string, bitSize int) ([]T, error) {
+	vals := []T{}
+	for _, v := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		i, err := strconv.ParseInt(v, 10, bitSize)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, T(i))
+	}
+	return vals, nil
+}
+
+func cfgbuildParseMap(s string) (map[string]string, error) {
+	mp := make(map[string]string)
+	for _, pair := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		kv := cfgbuildSplit(pair, cfgbuildKeyValueSeparator)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("key/value pair must contain exactly one %q separator",
+				cfgbuildKeyValueSeparator)
+		}
+		mp[kv[0]] = kv[1]
+	}
+	return mp, nil
+}
+
+func cfgbuildParseUint(s string, bitSize int) (uint64, error) {
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bitSize < 64 && u >= 1<<bitSize {
+		return 0, errors.New("overflow error")
+	}
+	return u, nil
+}
+
+func cfgbuildParseUints[T uint | uint8 | uint16 | uint32 | uint64](s string, bitSize int) ([]T, error) {
+	vals := []T{}
+	for _, v := range cfgbuildSplit(s, cfgbuildListSeparator) {
+		u, err := strconv.ParseUint(v, 10, bitSize)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, T(u))
+	}
+	return vals, nil
+}
+
+func cfgbuildSplit(s, sep string) []string {
+	vals := strings.Split(s, sep)
+	out := []string{}
+	for _, v := range vals {
+		out = append(out, strings.TrimSpace(v))
+	}
+	return out
+}
+
+// cfgbuildValidate runs the CfgBuildValidateContext() or CfgBuildValidate() function of cfg (if
+// it exists).
+func cfgbuildValidate(ctx context.Context, cfg any) error {
+	if validator, ok := cfg.(interface{ CfgBuildValidateContext(context.Context) error }); ok {
+		return validator.CfgBuildValidateContext(ctx)
+	}
+	if validator, ok := cfg.(interface{ CfgBuildValidate() error }); ok {
+		return validator.CfgBuildValidate()
+	}
+	return nil
+}
+
+// cfgbuildZero sets the value pointed to by p to the zero value for its type.
+func cfgbuildZero[T any](p *T) {
+	var zero T
+	*p = zero
+}
@@ -0,0 +1,121 @@
+package fixture
+
+import (
+	"testing"
+
+	"github.com/NathanBak/cfgbuild"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadConfigMatchesBuild checks that the generated LoadConfig function returns the same config
+// (or error) as the cfgbuild.Builder for the same variables.
+func TestLoadConfigMatchesBuild(t *testing.T) {
+	tsts := []struct {
+		name    string
+		vars    map[string]string
+		wantErr bool
+	}{
+		{"required only", map[string]string{"APP_PORT": "8080"}, false},
+		{"all fields", map[string]string{
+			"APP_NAME":          "svc",
+			"APP_PORT":          "8443",
+			"APP_SMALL":         "-12",
+			"APP_BIG":           "18446744073709551615",
+			"APP_MEDIUM":        "65535",
+			"APP_RATIO":         "1.25",
+			"APP_ENABLED":       "TrUe",
+			"APP_TIMEOUT":       "1500",
+			"APP_START":         "2024-01-02T03:04:05Z",
+			"APP_ENDPOINT":      "https://example.com/api?x=1",
+			"APP_LEVEL":         "warn",
+			"APP_HOSTS":         "a, b ,c",
+			"APP_COUNTS":        "1,-2,3",
+			"APP_WEIGHTS":       "0.5,1e3",
+			"APP_MASKS":         "1,65535",
+			"APP_KEY":           "s3cr3t",
+			"APP_LABELS":        "env:prod, team : core",
+			"APP_RETRIES":       "3",
+			"APP_OWNER":         "ops",
+			"APP_VERBOSE":       "false",
+			"APP_INTERVAL":      "2m",
+			"APP_CALLBACK":      "http://localhost/cb",
+			"APP_COLOR":         "Blue",
+			"APP_PRIORITY":      "200",
+			"APP_ADDR":          "10.0.0.1",
+			"APP_HOSTNAME":      "db.internal",
+			"APP_TOKEN":         "abc",
+			"APP_NOTE":          "hello",
+			"APP_TIER":          "gold",
+			"APP_LIMITS":        `{"max":10,"min":1}`,
+			"APP_DB_USER":       "admin",
+			"APP_DB_PASSWORD":   "hunter2",
+			"APP_DB_POOL_SIZE":  "20",
+			"APP_CACHE_SIZE":    "128",
+			"APP_USER":          "fallback",
+			"APP_POOL_SIZE":     "5",
+			"APP_CACHE_TTL":     "5s",
+			"APP_UNRELATED_VAR": "ignored",
+		}, false},
+		{"empty values", map[string]string{"APP_PORT": "1", "APP_NOTE": "", "APP_MEDIUM": "", "APP_NAME": ""}, false},
+		{"nested defaults", map[string]string{"APP_PORT": "1", "APP_CACHE_SIZE": "1"}, false},
+		{"deep nested", map[string]string{"APP_PORT": "1", "APP_BACKUP_USER": "backup",
+			"APP_CACHE_BACKUP_USER": "ignored"}, false},
+		{"missing required", map[string]string{}, true},
+		{"bad int", map[string]string{"APP_PORT": "eighty"}, true},
+		{"overflow", map[string]string{"APP_PORT": "1", "APP_SMALL": "128"}, true},
+		{"uint overflow", map[string]string{"APP_PORT": "1", "APP_PRIORITY": "256"}, true},
+		{"float overflow", map[string]string{"APP_PORT": "1", "APP_RATIO": "1e39"}, true},
+		{"bad bool", map[string]string{"APP_PORT": "1", "APP_ENABLED": "yes"}, true},
+		{"bad pointer bool", map[string]string{"APP_PORT": "1", "APP_VERBOSE": "1"}, true},
+		{"bad duration", map[string]string{"APP_PORT": "1", "APP_TIMEOUT": "soon"}, true},
+		{"bad time", map[string]string{"APP_PORT": "1", "APP_START": "yesterday"}, true},
+		{"bad list", map[string]string{"APP_PORT": "1", "APP_COUNTS": "1,x"}, true},
+		{"bad map", map[string]string{"APP_PORT": "1", "APP_LABELS": "a:b:c"}, true},
+		{"bad oneof", map[string]string{"APP_PORT": "1", "APP_LEVEL": "trace"}, true},
+		{"empty oneof", map[string]string{"APP_PORT": "1", "APP_TIER": ""}, true},
+		{"bad color", map[string]string{"APP_PORT": "1", "APP_COLOR": "mauve"}, true},
+		{"bad ip", map[string]string{"APP_PORT": "1", "APP_ADDR": "10.0.0"}, true},
+		{"bad json", map[string]string{"APP_PORT": "1", "APP_LIMITS": "{"}, true},
+		{"empty notempty", map[string]string{"APP_PORT": "1", "APP_TOKEN": ""}, true},
+		{"alias conflict", map[string]string{"APP_PORT": "1", "APP_HOST": "a", "APP_SERVER": "b"}, true},
+		{"nested validate", map[string]string{"APP_PORT": "1", "APP_DB_USER": "u", "APP_DB_POOL_SIZE": "0"}, true},
+		{"validate", map[string]string{"APP_PORT": "13"}, true},
+	}
+
+	for _, tst := range tsts {
+		t.Run(tst.name, func(t *testing.T) {
+			b := cfgbuild.Builder[*Config]{
+				Prefix:    "APP_",
+				Sources:   []cfgbuild.Source{cfgbuild.MapSource(tst.vars)},
+				OnWarning: func(err error) {},
+			}
+			want, wantErr := b.Build()
+
+			got, err := LoadConfig(func(name string) (string, bool) {
+				val, ok := tst.vars[name]
+				return val, ok
+			})
+
+			if tst.wantErr {
+				assert.Error(t, wantErr)
+				assert.EqualError(t, err, wantErr.Error())
+				return
+			}
+			assert.NoError(t, wantErr)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+			assert.True(t, got.InitCalled)
+		})
+	}
+}
+
+func TestLoadConfigNestedPrefixes(t *testing.T) {
+	vars := map[string]string{"APP_PORT": "1", "APP_BACKUP_USER": "backup",
+		"APP_CACHE_BACKUP_USER": "ignored"}
+	cfg, err := LoadConfig(func(name string) (string, bool) {
+		val, ok := vars[name]
+		return val, ok
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "backup", cfg.Cache.Backup.User)
+}
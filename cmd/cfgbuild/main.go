@@ -0,0 +1,133 @@
+// Command cfgbuild provides tools for configs which use cfgbuild tags.
+//
+// The gen subcommand generates a function which loads a config without using reflection.  It is
+// typically run using go generate:
+//
+//	//go:generate go run github.com/NathanBak/cfgbuild/cmd/cfgbuild gen -type Config
+//
+// For a type named Config the generated LoadConfig(lookup func(string) (string, bool)) function
+// behaves the same as cfgbuild.Builder[*Config].Build() for defaults, required fields, prefixes,
+// nested configs, and the init and validate functions.
+//
+// The env-example subcommand writes a commented .env.example file for a config type (the same as
+// cfgbuild.Builder.WriteEnvExample()):
+//
+//	go run github.com/NathanBak/cfgbuild/cmd/cfgbuild env-example -type Config -output .env.example
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const usage = `usage: cfgbuild gen -type Type[,Type...] [flags] [dir]
+       cfgbuild env-example -type Type [flags] [dir]
+
+The gen subcommand generates Load<Type> functions for the config types in the Go package in dir
+(default ".").  The env-example subcommand writes a .env.example file for the config type.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "gen":
+		err = runGen(os.Args[2:])
+	case "env-example":
+		err = runEnvExample(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cfgbuild %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// runGen parses the gen subcommand flags and writes the generated file.
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage+"\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	opts := genOptions{}
+	types := fs.String("type", "", "comma-separated list of config type names (required)")
+	fs.StringVar(&opts.output, "output", "", "output file name (default <type>_cfgbuild.go)")
+	fs.StringVar(&opts.prefix, "prefix", "", "prefix for all env var names (same as Builder.Prefix)")
+	fs.StringVar(&opts.tagKey, "tag", "envvar", "tag key (same as Builder.TagKey)")
+	fs.StringVar(&opts.listSeparator, "list-separator", ",",
+		"separator for list items (same as Builder.ListSeparator)")
+	fs.StringVar(&opts.keyValueSeparator, "kv-separator", ":",
+		"separator for map keys and values (same as Builder.KeyValueSeparator)")
+	_ = fs.Parse(args)
+
+	if *types == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	opts.types = strings.Split(*types, ",")
+
+	opts.dir = "."
+	if fs.NArg() > 0 {
+		opts.dir = fs.Arg(0)
+	}
+	if opts.output == "" {
+		opts.output = strings.ToLower(opts.types[0]) + "_cfgbuild.go"
+	}
+	if !filepath.IsAbs(opts.output) {
+		opts.output = filepath.Join(opts.dir, opts.output)
+	}
+
+	src, err := generate(opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(opts.output, src, 0o644)
+}
+
+// runEnvExample parses the env-example subcommand flags and writes the .env.example file.
+func runEnvExample(args []string) error {
+	fs := flag.NewFlagSet("env-example", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage+"\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	opts := envExampleOptions{}
+	output := fs.String("output", "", "output file name (default standard output)")
+	fs.StringVar(&opts.typeName, "type", "", "config type name (required)")
+	fs.StringVar(&opts.prefix, "prefix", "", "prefix for all env var names (same as Builder.Prefix)")
+	fs.StringVar(&opts.tagKey, "tag", "envvar", "tag key (same as Builder.TagKey)")
+	_ = fs.Parse(args)
+
+	if opts.typeName == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts.dir = "."
+	if fs.NArg() > 0 {
+		opts.dir = fs.Arg(0)
+	}
+
+	out, err := envExample(opts)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(*output, out, 0o644)
+}
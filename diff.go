@@ -0,0 +1,227 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RedactedValue replaces the values of fields with the "secret" tag attribute.
+const RedactedValue = "[REDACTED]"
+
+// A FieldChange describes a field which has a different value in two configs.
+type FieldChange struct {
+	// Path is the dotted list of field names leading to the field (ie Nested.MyVal).  For
+	// changed items of a list or map the index or key is appended (ie MyList[2] or MyMap[key]).
+	Path string
+	// EnvVar is the fully prefixed environment variable name for the field.
+	EnvVar string
+	// Old is the value in the old config (or nil if there is no value).
+	Old interface{}
+	// New is the value in the new config (or nil if there is no value).
+	New interface{}
+	// Static is true if the field has the "static" tag attribute meaning that the change cannot
+	// be applied without restarting.
+	Static bool
+}
+
+// Diff returns the tagged fields which have different values in the two configs.  Fields of
+// nested configs are compared, lists and maps are compared item by item, and the values of
+// fields with the "secret" tag attribute are replaced with RedactedValue (secret lists and maps
+// are compared as a whole).  Fields with the "-" tag value aren't read from env vars so they
+// aren't compared.
+func Diff[T any](oldCfg, newCfg T) []FieldChange {
+	b := Builder[T]{}
+	return b.Diff(oldCfg, newCfg)
+}
+
+// Diff returns the tagged fields which have different values in the two configs.
+func (b *Builder[T]) Diff(oldCfg, newCfg T) []FieldChange {
+	oldVal := reflect.ValueOf(oldCfg)
+	newVal := reflect.ValueOf(newCfg)
+	changes := []FieldChange{}
+
+	typ := b.cfgType()
+	if oldVal.IsValid() {
+		typ = oldVal.Type()
+	} else if newVal.IsValid() {
+		typ = newVal.Type()
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	_ = b.walkTaggedFields(typ, b.Prefix, true, func(f taggedField) error {
+		if f.name == "-" || f.name == ">" {
+			return nil
+		}
+
+		oldField, oldOK := fieldValue(f, oldVal)
+		newField, newOK := fieldValue(f, newVal)
+		_, secret := f.attr(tagAttrSecret)
+		_, static := f.attr(tagAttrStatic)
+
+		add := func(path string, o, n reflect.Value) {
+			changes = append(changes, FieldChange{
+				Path:   path,
+				EnvVar: f.envVarName(),
+				Old:    diffValue(o, secret),
+				New:    diffValue(n, secret),
+				Static: static,
+			})
+		}
+
+		switch {
+		case !oldOK && !newOK:
+		case oldOK != newOK:
+			add(f.path, oldField, newField)
+		case b.isCollection(f.field.Type) && secret:
+			// Secret lists and maps are reported as a whole so that no part of the value (such as
+			// a map key) appears in the path
+			changed := false
+			b.diffCollection(f.path, oldField, newField, func(string, reflect.Value, reflect.Value) {
+				changed = true
+			})
+			if changed {
+				add(f.path, oldField, newField)
+			}
+		case b.isCollection(f.field.Type):
+			b.diffCollection(f.path, oldField, newField, add)
+		case !equalValues(oldField.Interface(), newField.Interface()):
+			add(f.path, oldField, newField)
+		}
+		return nil
+	})
+
+	return changes
+}
+
+// isCollection returns true if values of the type should be compared item by item.  Named types
+// (such as net.IP) and byte slices are compared as a whole.
+func (b *Builder[T]) isCollection(typ reflect.Type) bool {
+	if typ.Name() != "" {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8 || b.Uint8Lists
+	case reflect.Map:
+		return true
+	}
+	return false
+}
+
+// diffCollection calls add for each item with a different value in the two lists or maps.
+func (b *Builder[T]) diffCollection(path string, oldVal, newVal reflect.Value,
+	add func(path string, o, n reflect.Value)) {
+
+	if oldVal.Kind() == reflect.Map {
+		keys := map[string]reflect.Value{}
+		for _, k := range oldVal.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range newVal.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			o := oldVal.MapIndex(keys[name])
+			n := newVal.MapIndex(keys[name])
+			if o.IsValid() != n.IsValid() ||
+				(o.IsValid() && !equalValues(o.Interface(), n.Interface())) {
+				add(fmt.Sprintf("%s[%s]", path, name), o, n)
+			}
+		}
+		return
+	}
+
+	for i := 0; i < oldVal.Len() || i < newVal.Len(); i++ {
+		var o, n reflect.Value
+		if i < oldVal.Len() {
+			o = oldVal.Index(i)
+		}
+		if i < newVal.Len() {
+			n = newVal.Index(i)
+		}
+		if o.IsValid() != n.IsValid() ||
+			(o.IsValid() && !equalValues(o.Interface(), n.Interface())) {
+			add(fmt.Sprintf("%s[%d]", path, i), o, n)
+		}
+	}
+}
+
+// equalValues returns true if the values are equal.  Certificate pools hold functions (which
+// reflect.DeepEqual never considers equal) so pools and certificates are compared with their Equal
+// methods.
+func equalValues(o, n interface{}) bool {
+	switch o := o.(type) {
+	case *x509.CertPool:
+		n, ok := n.(*x509.CertPool)
+		return ok && o.Equal(n)
+	case *x509.Certificate:
+		n, ok := n.(*x509.Certificate)
+		return ok && o.Equal(n)
+	case x509.Certificate:
+		n, ok := n.(x509.Certificate)
+		return ok && o.Equal(&n)
+	}
+	return reflect.DeepEqual(o, n)
+}
+
+// fieldValue returns the value of the field.  The returned bool is false if the field cannot be
+// reached (such as when the config or a nested config is nil).
+func fieldValue(f taggedField, v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return reflect.Value{}, false
+	}
+	fv, ok := f.value(v)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return fv, true
+}
+
+// diffValue returns the value to be reported in a FieldChange.
+func diffValue(v reflect.Value, secret bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if secret {
+		return RedactedValue
+	}
+	return v.Interface()
+}
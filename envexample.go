@@ -0,0 +1,129 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteEnvExample writes a commented .env.example file for the provided Config type to w.
+func WriteEnvExample[T any](w io.Writer) error {
+	b := Builder[T]{}
+	return b.WriteEnvExample(w)
+}
+
+// WriteEnvExample writes a commented .env.example file for the Builder's Config type to w.  Each
+// environment variable is written as a NAME=VALUE line where the value is the default (or empty
+// if there is no default).  Comments above each line list the field type, whether the value is
+// required, the description, and the allowed values.  Fields of nested configs are included with
+// the nested prefix applied.
+func (b *Builder[T]) WriteEnvExample(w io.Writer) error {
+	err := b.validateCfgTags()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	written := map[string]bool{}
+
+	err = b.walkTaggedFields(b.cfgType(), b.Prefix, true, func(f taggedField) error {
+		if f.name == "-" || f.name == ">" {
+			return nil
+		}
+
+		// The same env var may be associated with multiple fields, but only list it once
+		name := f.envVarName()
+		if written[name] {
+			return nil
+		}
+		written[name] = true
+
+		if len(written) > 1 {
+			fmt.Fprintln(bw)
+		}
+
+		typeDesc := f.field.Type.String()
+		if _, ok := f.attr(tagAttrUnmarshalJSON); ok {
+			typeDesc += " as JSON"
+		}
+		if f.layout != "" {
+			typeDesc += " as " + f.layout
+		}
+		if f.file {
+			typeDesc += " from file"
+		}
+		required := ""
+		if _, ok := f.attr(tagAttrRequired); ok {
+			required = ", required"
+		}
+		typeDesc += required
+		fmt.Fprintf(bw, "# %s (%s)\n", f.path, typeDesc)
+
+		if desc, ok := f.attr(tagAttrDescription); ok {
+			fmt.Fprintf(bw, "# %s\n", desc)
+		}
+
+		if aliases := f.aliasEnvVarNames(); len(aliases) > 0 {
+			fmt.Fprintf(bw, "# Deprecated names: %s\n", strings.Join(aliases, ", "))
+		}
+
+		if allowed := f.oneOf; allowed != nil {
+			fmt.Fprintf(bw, "# Allowed values: %s\n", strings.Join(allowed, ", "))
+		}
+
+		defaultVal, _ := f.attr(tagAttrDefault)
+		fmt.Fprintf(bw, "%s=%s\n", name, quoteEnvValue(defaultVal))
+
+		if keyName := f.prefix + f.keyVar; f.keyVar != "" && !written[keyName] {
+			written[keyName] = true
+			keyDesc := "PEM"
+			if f.file {
+				keyDesc = "PEM file"
+			}
+			fmt.Fprintf(bw, "\n# %s private key (%s%s)\n%s=\n", f.path, keyDesc, required, keyName)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// quoteEnvValue wraps the value in double quotes if it contains characters that would otherwise
+// be misread in a .env file.
+func quoteEnvValue(s string) string {
+	if strings.ContainsAny(s, " \t#\"'\\\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/NathanBak/cfgbuild"
+)
+
+type config struct {
+	Port     int      `envvar:"PORT,default=8080,description=Port the service listens on"`
+	LogLevel string   `envvar:"LOG_LEVEL,default=info,oneof=debug|info|warn|error"`
+	DB       dbConfig `envvar:">,prefix=DB_"`
+}
+
+type dbConfig struct {
+	Host     string `envvar:"HOST,required"`
+	Password string `envvar:"PASSWORD,required,description=Database password"`
+}
+
+// This main function shows how a service can offer an "env-example" subcommand that writes a
+// commented .env.example file for its config.  Run it with:
+//
+//	go run ./examples/envexample env-example > .env.example
+//
+// Expected output:
+//
+// # Port (int)
+// # Port the service listens on
+// PORT=8080
+//
+// # LogLevel (string)
+// # Allowed values: debug, info, warn, error
+// LOG_LEVEL=info
+//
+// # DB.Host (string, required)
+// DB_HOST=
+//
+// # DB.Password (string, required)
+// # Database password
+// DB_PASSWORD=
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "env-example" {
+		err := cfgbuild.WriteEnvExample[*config](os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg, err := cfgbuild.NewConfig[*config]()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("listening on port %d\n", cfg.Port)
+}
@@ -0,0 +1,123 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package timelayout parses and formats the times of time.Time fields with the "layout" tag
+// attribute.  It is shared by cfgbuild and the tagcheck analyzer.
+package timelayout
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// names are the names of the layouts which can be used with the "layout" tag attribute.
+var names = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// unixLayouts are the layouts for times given as a number of units since the Unix epoch.
+var unixLayouts = map[string]func(int64) time.Time{
+	"unix":   func(i int64) time.Time { return time.Unix(i, 0) },
+	"unixms": time.UnixMilli,
+	"unixus": time.UnixMicro,
+	"unixns": func(i int64) time.Time { return time.Unix(0, i) },
+}
+
+// Parse parses s the same way as a time.Time field with the "layout" tag attribute set to layout.
+// The layout can be the name of a layout (ie "DateOnly" or "unix") or a Go time layout (ie
+// "2006-01-02 15:04").  RFC3339 is used if the layout is empty.
+func Parse(layout, s string) (time.Time, error) {
+	if fromUnix, ok := unixLayouts[layout]; ok {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("time %q does not match the %s layout (an integer)", s, layout)
+		}
+		return fromUnix(i).UTC(), nil
+	}
+
+	if layout == "" {
+		return time.Parse(time.RFC3339, s)
+	}
+
+	goLayout, named := names[layout]
+	if !named {
+		goLayout = layout
+	}
+
+	t, err := time.Parse(goLayout, s)
+	if err != nil && named {
+		return t, fmt.Errorf("%s (layout %s)", err.Error(), layout)
+	}
+	return t, err
+}
+
+// Format is the inverse of Parse.
+func Format(layout string, t time.Time) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "unixus":
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case "unixns":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	case "":
+		return t.Format(time.RFC3339Nano)
+	}
+
+	if goLayout, ok := names[layout]; ok {
+		return t.Format(goLayout)
+	}
+	return t.Format(layout)
+}
+
+// IsUnix returns true if the layout is one of the layouts for an integer number of units since
+// the Unix epoch (such as "unix").
+func IsUnix(layout string) bool {
+	_, ok := unixLayouts[layout]
+	return ok
+}
@@ -0,0 +1,313 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NathanBak/cfgbuild/internal/timelayout"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect used by generated schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// A Schema is a JSON Schema describing the environment variables read when building a Config.
+// Each property is a fully prefixed environment variable name.  It can be converted to JSON using
+// json.Marshal().
+type Schema struct {
+	Schema     string                     `json:"$schema"`
+	Title      string                     `json:"title,omitempty"`
+	Type       string                     `json:"type"`
+	Properties map[string]*SchemaProperty `json:"properties"`
+	Required   []string                   `json:"required,omitempty"`
+}
+
+// A SchemaProperty describes a single environment variable.  Since environment variables are
+// always strings, the property type is always "string" and the Pattern (when set) describes the
+// values that can be parsed for the Go type of the field.
+type SchemaProperty struct {
+	Type             string   `json:"type"`
+	Description      string   `json:"description,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+	MinLength        int      `json:"minLength,omitempty"`
+	Format           string   `json:"format,omitempty"`
+	ContentMediaType string   `json:"contentMediaType,omitempty"`
+	Default          *string  `json:"default,omitempty"`
+	Enum             []string `json:"enum,omitempty"`
+	Deprecated       bool     `json:"deprecated,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema describing the environment variables for the provided Config
+// type.
+func JSONSchema[T any]() (*Schema, error) {
+	b := Builder[T]{}
+	return b.JSONSchema()
+}
+
+// JSONSchema returns a JSON Schema describing the environment variables for the Builder's Config
+// type.
+func (b *Builder[T]) JSONSchema() (*Schema, error) {
+	err := b.validateCfgTags()
+	if err != nil {
+		return nil, err
+	}
+
+	typ := b.cfgType()
+	schema := &Schema{
+		Schema:     JSONSchemaDraft,
+		Title:      typ.Name(),
+		Type:       "object",
+		Properties: map[string]*SchemaProperty{},
+	}
+
+	err = b.walkTaggedFields(typ, b.Prefix, true, func(f taggedField) error {
+		if f.name == "-" || f.name == ">" {
+			return nil
+		}
+
+		// The same env var may be associated with multiple fields, but only describe it once
+		name := f.envVarName()
+		if _, ok := schema.Properties[name]; ok {
+			return nil
+		}
+
+		prop := &SchemaProperty{Type: "string"}
+		prop.Description, _ = f.attr(tagAttrDescription)
+
+		// The value of a field with a "parser" is decoded before it is parsed (and checked against
+		// the "oneof" values) and the value of a "file" field is a file name so in both cases the
+		// env var value can only be described as a string
+		unchecked := f.parser != "" || f.file
+
+		_, unmarshalJSON := f.attr(tagAttrUnmarshalJSON)
+		switch {
+		case unchecked:
+		case unmarshalJSON:
+			prop.ContentMediaType = "application/json"
+		case f.unit == unitBytes:
+			prop.Pattern = "^(" + patternByteSize + ")$"
+		case f.layout != "":
+			prop.Pattern, prop.Format = layoutPattern(f.layout)
+		case f.schemes != nil && f.field.Type.Kind() != reflect.Slice:
+			prop.Pattern, prop.Format = schemesPattern(f.schemes), "uri"
+		default:
+			prop.Pattern, prop.Format = b.schemaPattern(f.fieldPlan)
+		}
+
+		if defaultVal, ok := f.attr(tagAttrDefault); ok {
+			prop.Default = &defaultVal
+		}
+
+		if !unchecked {
+			prop.Enum = f.oneOf
+		}
+
+		if b.emptyPolicy(f.fieldPlan) == EmptyAsError {
+			prop.MinLength = 1
+		}
+
+		if _, ok := f.attr(tagAttrRequired); ok {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = prop
+
+		// Deprecated aliases accept the same values
+		for _, alias := range f.aliasEnvVarNames() {
+			if _, ok := schema.Properties[alias]; ok {
+				continue
+			}
+			aliasProp := *prop
+			aliasProp.Description = fmt.Sprintf("Deprecated: use %s instead", name)
+			aliasProp.Default = nil
+			aliasProp.Deprecated = true
+			schema.Properties[alias] = &aliasProp
+		}
+
+		// The private key of a certificate is read from a separate env var
+		if f.keyVar != "" {
+			keyName := f.prefix + f.keyVar
+			schema.Properties[keyName] = &SchemaProperty{Type: "string",
+				Description: fmt.Sprintf("Private key for %s", name)}
+			if _, ok := f.attr(tagAttrRequired); ok {
+				schema.Required = append(schema.Required, keyName)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// Patterns (without anchors) matching the values accepted for various types.
+const (
+	patternInt          = `[+-]?[0-9]+`
+	patternUint         = `[0-9]+`
+	patternUintPrefixed = `0[xX](_?[0-9a-fA-F])+|0[oO]?(_?[0-7])+|0[bB](_?[01])+|[0-9](_?[0-9])*`
+	patternFloat        = `[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?`
+	patternBool         = `[Tt][Rr][Uu][Ee]|[Ff][Aa][Ll][Ss][Ee]`
+	patternBoolExt      = `[01TtFf]|[Yy][Ee][Ss]|[Nn][Oo]|[Oo][Nn]|[Oo][Ff][Ff]`
+	patternDuration     = `[+-]?[0-9]+|[+-]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+`
+	patternURL          = `([a-zA-Z][a-zA-Z0-9+.-]*:)?[^\s]*`
+	patternByteSize     = `\s*([0-9]+(\.[0-9]*)?|\.[0-9]+)\s*([kKmMgGtTpPeE][iI]?)?[bB]?\s*`
+	patternTime         = `[0-9]{4}-[0-9]{2}-[0-9]{2}[Tt][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?([Zz]|[+-][0-9]{2}:[0-9]{2})`
+)
+
+// layoutPattern returns an anchored pattern and a format for times with the layout from the
+// "layout" tag attribute.
+func layoutPattern(layout string) (pattern, format string) {
+	switch layout {
+	case "RFC3339", "RFC3339Nano":
+		return "^(" + patternTime + ")$", "date-time"
+	case "DateOnly", time.DateOnly:
+		return "^[0-9]{4}-[0-9]{2}-[0-9]{2}$", "date"
+	}
+	if timelayout.IsUnix(layout) {
+		return "^(" + patternInt + ")$", ""
+	}
+	return "", ""
+}
+
+// schemesPattern returns an anchored pattern for URLs with one of the schemes.
+func schemesPattern(schemes []string) string {
+	quoted := []string{}
+	for _, scheme := range schemes {
+		quoted = append(quoted, regexp.QuoteMeta(scheme))
+	}
+	return "^(" + strings.Join(quoted, "|") + `):[^\s]*$`
+}
+
+// schemaPattern returns an anchored pattern and a format for the values which can be parsed for
+// the field.  Empty strings are returned if there is nothing more specific than "string".
+func (b *Builder[T]) schemaPattern(f *fieldPlan) (pattern, format string) {
+	typ := f.field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ {
+	case reflect.TypeOf(url.URL{}):
+		return "^" + patternURL + "$", "uri-reference"
+	case reflect.TypeOf(regexp.Regexp{}):
+		return "", "regex"
+	case reflect.TypeOf([]uint8{}):
+		if !b.Uint8Lists {
+			return "", ""
+		}
+	}
+
+	if typ.Kind() == reflect.Slice {
+		elem := b.elemPattern(f, typ.Elem())
+		if elem == "" {
+			return "", ""
+		}
+		sep := b.ListSeparator
+		if sep == "" {
+			sep = DefaultListSeparator
+		}
+		item := `\s*(` + elem + `)\s*`
+		return "^" + item + "(" + regexp.QuoteMeta(sep) + item + ")*$", ""
+	}
+
+	pattern = b.elemPattern(f, typ)
+	if pattern == "" {
+		return "", ""
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		format = "date-time"
+	}
+	return "^(" + pattern + ")$", format
+}
+
+// elemPattern returns an unanchored pattern for a scalar type of the field (or an empty string if
+// there is no pattern for the type).  The field determines the base of integers.
+func (b *Builder[T]) elemPattern(f *fieldPlan, typ reflect.Type) string {
+	switch typ {
+	case reflect.TypeOf(time.Time{}):
+		return patternTime
+	case reflect.TypeOf(time.Duration(0)):
+		return patternDuration
+	case reflect.TypeOf(ByteSize(0)):
+		return patternByteSize
+	}
+
+	// Types that unmarshal themselves may accept anything
+	if reflect.PointerTo(typ).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		return ""
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if b.BoolSyntax == BoolLenient {
+			return patternBool + "|" + patternBoolExt
+		}
+		return patternBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		base := b.integerBase(f, typ)
+		if base == 10 {
+			return patternInt
+		}
+		return `[+-]?(` + integerPattern(base) + `)`
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerPattern(b.integerBase(f, typ))
+	case reflect.Float32, reflect.Float64:
+		return patternFloat
+	}
+	return ""
+}
+
+// integerPattern returns an unanchored pattern for the unsigned integers which can be parsed in the
+// base (see integerBase).  The prefix for the base (such as 0x for base 16) is optional.
+func integerPattern(base int) string {
+	switch base {
+	case 0:
+		return patternUintPrefixed
+	case 10:
+		return patternUint
+	}
+
+	digits := "[0-" + strconv.Itoa(base-1) + "]"
+	if base > 10 {
+		last := string(rune('a' + base - 11))
+		digits = "[0-9a-" + last + "A-" + strings.ToUpper(last) + "]"
+	}
+	if prefix, ok := basePrefixes[base]; ok {
+		digits = "(0[" + prefix[1:] + strings.ToUpper(prefix[1:]) + "])?" + digits
+	}
+	return digits + "+"
+}
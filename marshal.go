@@ -0,0 +1,301 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NathanBak/cfgbuild/internal/timelayout"
+)
+
+// Marshal is the inverse of building a config.  It accepts a Config and returns a map of
+// environment variable names to values which would recreate the Config when built.
+func Marshal(cfg interface{}) (map[string]string, error) {
+	b := Builder[interface{}]{}
+	return b.Marshal(cfg)
+}
+
+// MarshalEnviron accepts a Config and returns the environment variables which would recreate the
+// Config in "KEY=VALUE" form (sorted by key).  The result can be used as the Env of an exec.Cmd.
+func MarshalEnviron(cfg interface{}) ([]string, error) {
+	b := Builder[interface{}]{}
+	return b.MarshalEnviron(cfg)
+}
+
+// Marshal returns a map of environment variable names to values which would recreate the provided
+// Config when built by the Builder.  The Builder's TagKey, ListSeparator, KeyValueSeparator, and
+// Uint8Lists settings are respected.  Fields with nil values (and empty slices and maps) are
+// omitted as are the fields of nil nested configs.
+func (b *Builder[T]) Marshal(cfg T) (map[string]string, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("unable to marshal nil %T", cfg)
+		}
+		v = v.Elem()
+	}
+
+	out := map[string]string{}
+
+	err := b.walkTaggedFields(v.Type(), b.Prefix, true, func(f taggedField) error {
+		if f.name == "-" || f.name == ">" {
+			return nil
+		}
+
+		fv, ok := f.value(v)
+		if !ok {
+			return nil
+		}
+
+		name := f.envVarName()
+		s, ok, err := b.formatTaggedField(f, fv)
+		if err != nil {
+			return fmt.Errorf("error marshaling %q (%s)", name, err.Error())
+		}
+		if !ok {
+			return nil
+		}
+
+		if prev, found := out[name]; found && prev != s {
+			return fmt.Errorf("error marshaling %q (fields have conflicting values %q and %q)",
+				name, prev, s)
+		}
+		out[name] = s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// MarshalEnviron returns the environment variables which would recreate the provided Config in
+// "KEY=VALUE" form (sorted by key).
+func (b *Builder[T]) MarshalEnviron(cfg T) ([]string, error) {
+	m, err := b.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	environ := make([]string, 0, len(m))
+	for k, v := range m {
+		environ = append(environ, k+"="+v)
+	}
+	sort.Strings(environ)
+	return environ, nil
+}
+
+// formatTaggedField returns the string representation of a tagged field value.  The returned bool
+// is false if the field should be omitted.
+func (b *Builder[T]) formatTaggedField(f taggedField, v reflect.Value) (string, bool, error) {
+	// The value of a field with the "file" attribute was read from a file which can't be recreated
+	if f.file {
+		return "", false, nil
+	}
+
+	var s string
+	var ok bool
+	if _, tagFound := f.attr(tagAttrUnmarshalJSON); tagFound {
+		buf, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "", false, err
+		}
+		s, ok = string(buf), true
+	} else {
+		var err error
+		s, ok, err = b.formatFieldValue(f.fieldPlan, v)
+		if err != nil || !ok {
+			return "", false, err
+		}
+	}
+
+	// The value is encoded so that the "parser" decoder returns the formatted value
+	if f.parser != "" {
+		encode := b.encoder(f.parser)
+		if encode == nil {
+			return "", false, fmt.Errorf("the %q decoder can't be reversed", f.parser)
+		}
+		s = encode(s)
+	}
+	return s, ok, nil
+}
+
+// formatFieldValue is the inverse of setFieldValue and returns the string representation of the
+// value.  The returned bool is false if the value is nil or empty and should be omitted.
+func (b *Builder[T]) formatFieldValue(f *fieldPlan, v reflect.Value) (string, bool, error) {
+	sep := b.ListSeparator
+	if sep == "" {
+		sep = DefaultListSeparator
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Type() {
+
+	case reflect.TypeOf(time.Time{}):
+		return timelayout.Format(f.layout, v.Interface().(time.Time)), true, nil
+
+	case reflect.TypeOf(time.Duration(0)):
+		return v.Interface().(time.Duration).String(), true, nil
+
+	case reflect.TypeOf(time.Location{}):
+		loc := v.Interface().(time.Location)
+		return loc.String(), true, nil
+
+	case reflect.TypeOf(url.URL{}):
+		u := v.Interface().(url.URL)
+		return u.String(), true, nil
+
+	case reflect.TypeOf(tls.Certificate{}), reflect.TypeOf(x509.CertPool{}):
+		// private keys aren't exported and the certificates of a pool can't be listed
+		return "", false, nil
+
+	case reflect.TypeOf(x509.Certificate{}):
+		cert := v.Interface().(x509.Certificate)
+		return formatCertificates([]*x509.Certificate{&cert}), true, nil
+
+	case reflect.TypeOf([]*x509.Certificate{}):
+		if v.Len() == 0 {
+			return "", false, nil
+		}
+		return formatCertificates(v.Interface().([]*x509.Certificate)), true, nil
+
+	case reflect.TypeOf(net.IPNet{}):
+		ipNet := v.Interface().(net.IPNet)
+		return ipNet.String(), true, nil
+
+	case reflect.TypeOf([]uint8{}):
+		if !b.Uint8Lists {
+			if v.Len() == 0 {
+				return "", false, nil
+			}
+			return string(v.Bytes()), true, nil
+		}
+
+	case reflect.TypeOf(map[string]string{}):
+		if v.Len() == 0 {
+			return "", false, nil
+		}
+
+		kvsep := b.KeyValueSeparator
+		if kvsep == "" {
+			kvsep = DefaultKeyValueSeparator
+		}
+
+		m := v.Interface().(map[string]string)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		pairs := []string{}
+		for _, k := range keys {
+			for _, s := range []string{k, m[k]} {
+				if strings.Contains(s, sep) || strings.Contains(s, kvsep) {
+					return "", false, fmt.Errorf("map entry %q contains a separator", s)
+				}
+			}
+			pairs = append(pairs, k+kvsep+m[k])
+		}
+		return strings.Join(pairs, sep), true, nil
+	}
+
+	if v.CanInterface() {
+		textMarshaler, ok := v.Interface().(encoding.TextMarshaler)
+		if !ok && v.CanAddr() {
+			textMarshaler, ok = v.Addr().Interface().(encoding.TextMarshaler)
+		}
+		if ok {
+			buf, err := textMarshaler.MarshalText()
+			if err != nil {
+				return "", false, err
+			}
+			return string(buf), true, nil
+		}
+	}
+
+	if v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return "", false, nil
+		}
+		items := []string{}
+		for i := 0; i < v.Len(); i++ {
+			s, _, err := b.formatFieldValue(f, v.Index(i))
+			if err != nil {
+				return "", false, err
+			}
+			if strings.Contains(s, sep) {
+				return "", false, fmt.Errorf("list item %q contains the list separator", s)
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, sep), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), b.formatBase(f, v.Type())), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), b.formatBase(f, v.Type())), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true, nil
+	case reflect.String:
+		return v.String(), true, nil
+	}
+
+	return "", false, fmt.Errorf("unsupported type/kind \"%s/%s\"",
+		v.Type().String(), v.Kind().String())
+}
+
+// formatBase returns the base used to format integers so that they are parsed correctly.
+func (b *Builder[T]) formatBase(f *fieldPlan, typ reflect.Type) int {
+	if base := b.integerBase(f, typ); base != 0 {
+		return base
+	}
+	return 10
+}
@@ -0,0 +1,198 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// builtinDecoders are the decoders which can be used with the "parser" tag attribute without
+// being added to the Builder Decoders.
+var builtinDecoders = map[string]func(string) (string, error){
+	// base64 decodes standard base64 (with or without padding)
+	"base64": func(s string) (string, error) {
+		b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+		return string(b), err
+	},
+	// base64url decodes URL-safe base64 (with or without padding)
+	"base64url": func(s string) (string, error) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		return string(b), err
+	},
+	"hex": func(s string) (string, error) {
+		b, err := hex.DecodeString(s)
+		return string(b), err
+	},
+	"lower": func(s string) (string, error) { return strings.ToLower(s), nil },
+	"upper": func(s string) (string, error) { return strings.ToUpper(s), nil },
+	"trim":  func(s string) (string, error) { return strings.TrimSpace(s), nil },
+}
+
+// builtinEncoders reverse the built-in decoders so that marshaled values decode to the field value.
+// The lower, upper, and trim decoders don't change values which they have already decoded.
+var builtinEncoders = map[string]func(string) string{
+	"base64":    func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"base64url": func(s string) string { return base64.URLEncoding.EncodeToString([]byte(s)) },
+	"hex":       func(s string) string { return hex.EncodeToString([]byte(s)) },
+	"lower":     func(s string) string { return s },
+	"upper":     func(s string) string { return s },
+	"trim":      func(s string) string { return s },
+}
+
+// decoder returns the named decoder from the Builder Decoders (or the built-in decoders).  Nil is
+// returned if there is no decoder with the name.
+func (b *Builder[T]) decoder(name string) func(string) (string, error) {
+	if d, ok := b.Decoders[name]; ok {
+		return d
+	}
+	return builtinDecoders[name]
+}
+
+// encoder returns the function which reverses the named built-in decoder.  Nil is returned for
+// decoders from the Builder Decoders since they can't be reversed.
+func (b *Builder[T]) encoder(name string) func(string) string {
+	if _, ok := b.Decoders[name]; ok {
+		return nil
+	}
+	return builtinEncoders[name]
+}
+
+// parseRegistered parses s using the registered Parsers.  A Parser is used if it is registered for
+// typ or for the item type of a slice, the key or value type of a map, or the element type of a
+// pointer.  The returned bool is false if no registered Parser applies to typ.
+func (b *Builder[T]) parseRegistered(f *fieldPlan, typ reflect.Type, s string) (
+	reflect.Value, bool, error) {
+
+	if len(b.Parsers) == 0 {
+		return reflect.Value{}, false, nil
+	}
+
+	if parser, ok := b.Parsers[typ]; ok {
+		v, err := callParser(typ, parser, s)
+		return v, true, err
+	}
+
+	switch typ.Kind() {
+
+	case reflect.Slice:
+		if _, ok := b.Parsers[typ.Elem()]; !ok {
+			return reflect.Value{}, false, nil
+		}
+
+		vals := reflect.MakeSlice(typ, 0, 0)
+		for _, item := range split(s, b.ListSeparator) {
+			v, err := b.parseElement(f, typ.Elem(), item)
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			vals = reflect.Append(vals, v)
+		}
+		return vals, true, nil
+
+	case reflect.Map:
+		_, keyOK := b.Parsers[typ.Key()]
+		_, valOK := b.Parsers[typ.Elem()]
+		if !keyOK && !valOK {
+			return reflect.Value{}, false, nil
+		}
+
+		kvsep := b.KeyValueSeparator
+		if kvsep == "" {
+			kvsep = DefaultKeyValueSeparator
+		}
+
+		mp := reflect.MakeMap(typ)
+		for _, pair := range split(s, b.ListSeparator) {
+			kv := split(pair, kvsep)
+			if len(kv) != 2 {
+				return reflect.Value{}, true,
+					fmt.Errorf("key/value pair must contain exactly one %q separator", kvsep)
+			}
+			k, err := b.parseElement(f, typ.Key(), kv[0])
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			v, err := b.parseElement(f, typ.Elem(), kv[1])
+			if err != nil {
+				return reflect.Value{}, true, err
+			}
+			mp.SetMapIndex(k, v)
+		}
+		return mp, true, nil
+
+	case reflect.Pointer:
+		parser, ok := b.Parsers[typ.Elem()]
+		if !ok {
+			return reflect.Value{}, false, nil
+		}
+
+		v, err := callParser(typ.Elem(), parser, s)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		p := reflect.New(typ.Elem())
+		p.Elem().Set(v)
+		return p, true, nil
+	}
+
+	return reflect.Value{}, false, nil
+}
+
+// parseElement parses s as a value of type typ using the registered Parser for the type (or the
+// built-in parsing if there is no registered Parser).
+func (b *Builder[T]) parseElement(f *fieldPlan, typ reflect.Type, s string) (reflect.Value, error) {
+	if parser, ok := b.Parsers[typ]; ok {
+		return callParser(typ, parser, s)
+	}
+
+	v := reflect.New(typ).Elem()
+	err := b.setFieldValue(f, v, s)
+	return v, err
+}
+
+// callParser runs the parser and checks that the returned value can be used for type typ.  A nil
+// value is treated as the zero value for the type.
+func callParser(typ reflect.Type, parser func(string) (any, error), s string) (reflect.Value, error) {
+	x, err := parser(s)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if x == nil {
+		return reflect.Zero(typ), nil
+	}
+
+	v := reflect.ValueOf(x)
+	if !v.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("parser for type %s returned a %s", typ, v.Type())
+	}
+	return v, nil
+}
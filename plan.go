@@ -0,0 +1,194 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// planCache holds the *typePlan for each planKey.  Plans are compiled the first time a type is
+// used and then shared by all Builders (and goroutines).
+var planCache sync.Map
+
+// A planKey identifies a typePlan.  The same struct type has a different plan for each tag key.
+type planKey struct {
+	typ    reflect.Type
+	tagKey string
+}
+
+// A typePlan holds the parsed tags of the fields of a struct type so that the tags don't have to
+// be parsed each time a config is built.
+type typePlan struct {
+	// fields are the fields which have the tag key set (in declaration order)
+	fields []*fieldPlan
+	// err is the first tag syntax error found in the fields (if any)
+	err error
+}
+
+// A fieldPlan holds the parsed tag value of a struct field.
+type fieldPlan struct {
+	field    reflect.StructField
+	tagValue string
+	// name is the env var name portion of the tag value (or "-" or ">")
+	name string
+	// aliases are deprecated alternatives to the env var name
+	aliases []string
+	// names is the env var name followed by the aliases
+	names []string
+	// attrs are the tag attributes in the order they are listed in the tag value
+	attrs []fieldAttr
+
+	defaultVal    string
+	hasDefault    bool
+	required      bool
+	secret        bool
+	unmarshalJSON bool
+	nestedPrefix  string
+	oneOf         []string
+	hostPort      bool
+	// keyVar is the name of the env var holding the private key from the "key" attribute
+	keyVar string
+	// file is true if the value is the name of a file to read
+	file bool
+	// schemes are the allowed URL schemes (lowercased) from the "schemes" attribute
+	schemes []string
+	// parser is the name of the decoder from the "parser" attribute
+	parser string
+	// layout is the time layout from the "layout" attribute
+	layout string
+	// unit is the unit of integer values from the "unit" attribute
+	unit string
+	// base is the integer base from the "base" attribute if hasBase is true
+	base    int
+	hasBase bool
+	// emptyPolicy overrides the Builder EmptyPolicy if hasEmptyPolicy is true
+	emptyPolicy    EmptyPolicy
+	hasEmptyPolicy bool
+}
+
+// A fieldAttr is a single attribute parsed from a tag value.
+type fieldAttr struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// attr returns the value of the attribute and a bool indicator as to whether or not the attribute
+// exists in the tag value.
+func (f *fieldPlan) attr(a tagAttr) (string, bool) {
+	for _, fa := range f.attrs {
+		if fa.name == string(a) {
+			return fa.value, true
+		}
+	}
+	return "", false
+}
+
+// plan returns the typePlan for the struct type typ.
+func (b *Builder[T]) plan(typ reflect.Type) *typePlan {
+	key := planKey{typ: typ, tagKey: b.getTagKey()}
+	if p, ok := planCache.Load(key); ok {
+		return p.(*typePlan)
+	}
+	p, _ := planCache.LoadOrStore(key, compilePlan(typ, key.tagKey))
+	return p.(*typePlan)
+}
+
+// compilePlan parses the tags of the fields of the struct type typ.
+func compilePlan(typ reflect.Type, tagKey string) *typePlan {
+	p := &typePlan{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tagValue, ok := field.Tag.Lookup(tagKey)
+		if !ok {
+			continue
+		}
+
+		f := compileFieldPlan(field, tagValue)
+		if p.err == nil {
+			p.err = validateFieldPlan(f, tagKey)
+		}
+		p.fields = append(p.fields, f)
+	}
+	return p
+}
+
+// compileFieldPlan parses the tag value of a field.
+func compileFieldPlan(field reflect.StructField, tagValue string) *fieldPlan {
+	parts := strings.Split(tagValue, ",")
+	names := strings.Split(parts[0], "|")
+
+	f := &fieldPlan{
+		field:    field,
+		tagValue: tagValue,
+		name:     names[0],
+		aliases:  names[1:],
+		names:    names,
+	}
+
+	for _, part := range parts[1:] {
+		name, value, hasValue := strings.Cut(part, "=")
+		f.attrs = append(f.attrs, fieldAttr{name: name, value: value, hasValue: hasValue})
+	}
+
+	f.defaultVal, f.hasDefault = f.attr(tagAttrDefault)
+	_, f.required = f.attr(tagAttrRequired)
+	_, f.secret = f.attr(tagAttrSecret)
+	_, f.unmarshalJSON = f.attr(tagAttrUnmarshalJSON)
+	f.nestedPrefix, _ = f.attr(tagAttrPrefix)
+	_, f.hostPort = f.attr(tagAttrHostPort)
+	f.keyVar, _ = f.attr(tagAttrKey)
+	_, f.file = f.attr(tagAttrFile)
+	f.parser, _ = f.attr(tagAttrParser)
+	f.layout, _ = f.attr(tagAttrLayout)
+	f.unit, _ = f.attr(tagAttrUnit)
+	if base, ok := f.attr(tagAttrBase); ok {
+		// invalid values are reported by checkTagAttributes
+		f.base, _ = strconv.Atoi(base)
+		f.hasBase = true
+	}
+	if schemes, ok := f.attr(tagAttrSchemes); ok {
+		f.schemes = strings.Split(strings.ToLower(schemes), "|")
+	}
+	if oneOf, ok := f.attr(tagAttrOneOf); ok {
+		f.oneOf = strings.Split(oneOf, "|")
+	}
+
+	if _, ok := f.attr(tagAttrNotEmpty); ok {
+		f.emptyPolicy, f.hasEmptyPolicy = EmptyAsError, true
+	} else if _, ok := f.attr(tagAttrAllowEmpty); ok {
+		f.emptyPolicy, f.hasEmptyPolicy = EmptyAsZero, true
+	} else if _, ok := f.attr(tagAttrUnsetIfEmpty); ok {
+		f.emptyPolicy, f.hasEmptyPolicy = EmptyAsUnset, true
+	}
+
+	return f
+}
@@ -0,0 +1,225 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// A Source provides the values used when building a Config.  By default a Builder reads the
+// process environment variables, but values can also come from files, remote endpoints, secret
+// stores, etc by setting the Builder Sources.
+type Source interface {
+	// Lookup returns the value for the provided (fully prefixed) environment variable name.  The
+	// returned bool is false if the Source does not have a value for the name.  Sources which may
+	// block should return when the context is done.
+	Lookup(ctx context.Context, name string) (string, bool, error)
+}
+
+// EnvSource is a Source which reads the process environment variables.
+type EnvSource struct{}
+
+// Lookup returns the value of the environment variable with the provided name.
+func (EnvSource) Lookup(ctx context.Context, name string) (string, bool, error) {
+	val, ok := os.LookupEnv(name)
+	return val, ok, nil
+}
+
+// MapSource is a Source which reads values from a map.  It is useful for tests and for values
+// which have already been loaded from elsewhere.
+type MapSource map[string]string
+
+// Lookup returns the map value for the provided name.
+func (m MapSource) Lookup(ctx context.Context, name string) (string, bool, error) {
+	val, ok := m[name]
+	return val, ok, nil
+}
+
+// A WatchableSource is a Source whose values are read from files.  A Watcher checks the paths
+// for changes and rebuilds the Config when they change.
+type WatchableSource interface {
+	Source
+	// WatchPaths returns the files and directories which hold the values.
+	WatchPaths() []string
+}
+
+// A FileSource is a Source which reads values from a .env style file.  The file is parsed using
+// github.com/joho/godotenv so it has the same format as the files read by godotenv.Load().  The
+// file is only re-read when it has been modified.
+type FileSource struct {
+	// Path of the file.
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	values  map[string]string
+}
+
+// Lookup returns the value for the provided name from the file.
+func (s *FileSource) Lookup(ctx context.Context, name string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return "", false, err
+	}
+
+	if s.values == nil || !info.ModTime().Equal(s.modTime) || info.Size() != s.size {
+		buf, err := os.ReadFile(s.Path)
+		if err != nil {
+			return "", false, err
+		}
+		values, err := godotenv.Parse(bytes.NewReader(buf))
+		if err != nil {
+			return "", false, fmt.Errorf("unable to parse %q (%w)", s.Path, err)
+		}
+		s.values = values
+		s.modTime = info.ModTime()
+		s.size = info.Size()
+	}
+
+	val, ok := s.values[name]
+	return val, ok, nil
+}
+
+// WatchPaths returns the path of the file.
+func (s *FileSource) WatchPaths() []string {
+	return []string{s.Path}
+}
+
+// A DirSource is a Source which reads values from a directory containing one file per variable
+// where the file name is the variable name and the file contents is the value (such as a
+// Kubernetes ConfigMap or Secret mounted as a volume).  A single trailing newline is removed from
+// the value.
+type DirSource struct {
+	// Dir is the path of the directory.
+	Dir string
+}
+
+// Lookup returns the contents of the file with the provided name in the directory.
+func (s DirSource) Lookup(ctx context.Context, name string) (string, bool, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", false, nil
+	}
+
+	buf, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return trimNewline(string(buf)), true, nil
+}
+
+// trimNewline removes a single trailing newline (\n or \r\n) from the contents of a file.
+func trimNewline(s string) string {
+	if !strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+}
+
+// WatchPaths returns the path of the directory.
+func (s DirSource) WatchPaths() []string {
+	return []string{s.Dir}
+}
+
+// A ListableSource is a Source which can list the names of all of its variables.  Only listable
+// Sources are checked for unknown variables in strict mode.
+type ListableSource interface {
+	Source
+	// Names returns the names of all variables in the Source.
+	Names(ctx context.Context) ([]string, error)
+}
+
+// Names returns the names of all the process environment variables.
+func (EnvSource) Names(ctx context.Context) ([]string, error) {
+	names := []string{}
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Names returns the keys of the map.
+func (m MapSource) Names(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Names returns the names of the variables in the file.
+func (s *FileSource) Names(ctx context.Context) ([]string, error) {
+	// a lookup ensures the values have been loaded
+	if _, _, err := s.Lookup(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Names returns the names of the files in the directory (excluding hidden files such as the
+// "..data" link used by Kubernetes).
+func (s DirSource) Names(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
@@ -0,0 +1,219 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An UnknownVarsError lists variables which have a prefix used by a config but which are not
+// used by any field.
+type UnknownVarsError struct {
+	// Names of the unknown variables.
+	Names []string
+	// Suggestions maps unknown variable names to the closest valid name (if there is one).
+	Suggestions map[string]string
+}
+
+func (e *UnknownVarsError) Error() string {
+	describe := func(name string) string {
+		if suggestion, ok := e.Suggestions[name]; ok {
+			return fmt.Sprintf("%q (did you mean %q?)", name, suggestion)
+		}
+		return fmt.Sprintf("%q", name)
+	}
+
+	if len(e.Names) == 1 {
+		return "unknown variable " + describe(e.Names[0])
+	}
+
+	descriptions := []string{}
+	for _, name := range e.Names {
+		descriptions = append(descriptions, describe(name))
+	}
+	return "unknown variables " + strings.Join(descriptions, ", ")
+}
+
+// checkUnknownVars looks for variables that have a prefix used by the config, but are not used
+// by any field.  Depending on the Strict setting, an error is returned or a warning is issued.
+func (b *Builder[T]) checkUnknownVars() error {
+	if b.Strict == StrictOff {
+		return nil
+	}
+	b.logFunctionStart()
+	defer b.logFunctionFinish()
+
+	known := map[string]bool{}
+	prefixes := map[string]bool{}
+	if b.Prefix != "" {
+		prefixes[b.Prefix] = true
+	}
+
+	err := b.walkTaggedFields(b.cfgType(), b.Prefix, true, func(f taggedField) error {
+		switch f.name {
+		case "-":
+		case ">":
+			nestedPrefix, _ := f.attr(tagAttrPrefix)
+			if b.Prefix+nestedPrefix != "" {
+				prefixes[b.Prefix+nestedPrefix] = true
+			}
+		default:
+			known[f.envVarName()] = true
+			for _, alias := range f.aliasEnvVarNames() {
+				known[alias] = true
+			}
+			if f.keyVar != "" {
+				known[f.prefix+f.keyVar] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	names, err := b.listSourceNames()
+	if err != nil {
+		return err
+	}
+
+	unknown := &UnknownVarsError{Suggestions: map[string]string{}}
+	for _, name := range names {
+		if known[name] {
+			continue
+		}
+		for prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				unknown.Names = append(unknown.Names, name)
+				if suggestion, ok := closestName(name, known); ok {
+					unknown.Suggestions[name] = suggestion
+				}
+				break
+			}
+		}
+	}
+
+	if len(unknown.Names) == 0 {
+		return nil
+	}
+
+	if b.Strict == StrictError {
+		return unknown
+	}
+	b.warn(unknown)
+	return nil
+}
+
+// listSourceNames returns the sorted names of the variables in the Sources which implement
+// ListableSource.
+func (b *Builder[T]) listSourceNames() ([]string, error) {
+	sources := b.Sources
+	if len(sources) == 0 {
+		sources = []Source{EnvSource{}}
+	}
+
+	found := map[string]bool{}
+	for _, src := range sources {
+		ls, ok := src.(ListableSource)
+		if !ok {
+			continue
+		}
+		names, err := ls.Names(b.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			found[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// closestName returns the known name with the smallest edit distance from the provided name.  The
+// returned bool is false if no known name is close enough to be a likely typo.
+func closestName(name string, known map[string]bool) (string, bool) {
+	best := ""
+	bestDistance := -1
+	for k := range known {
+		d := editDistance(name, k)
+		if bestDistance < 0 || d < bestDistance || (d == bestDistance && k < best) {
+			best = k
+			bestDistance = d
+		}
+	}
+
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	return best, bestDistance >= 0 && bestDistance <= maxDistance
+}
+
+// editDistance returns the Levenshtein distance between the two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, i := range rest {
+		if i < m {
+			m = i
+		}
+	}
+	return m
+}
@@ -0,0 +1,14 @@
+// Command cfgbuild-vet checks cfgbuild struct tags.  It is intended to be run by go vet:
+//
+//	cd tagcheck && go install ./cmd/cfgbuild-vet
+//	go vet -vettool=$(which cfgbuild-vet) ./...
+package main
+
+import (
+	"github.com/NathanBak/cfgbuild/tagcheck"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(tagcheck.Analyzer)
+}
@@ -0,0 +1,16 @@
+module github.com/NathanBak/cfgbuild/tagcheck
+
+go 1.22.0
+
+require (
+	github.com/NathanBak/cfgbuild v0.0.0-00010101000000-000000000000
+	golang.org/x/tools v0.26.0
+)
+
+require (
+	github.com/joho/godotenv v1.4.0 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
+
+replace github.com/NathanBak/cfgbuild => ../
@@ -0,0 +1,481 @@
+// Package tagcheck defines an Analyzer which reports problems with cfgbuild struct tags.
+//
+// The Analyzer finds the problems which would otherwise cause Build() to fail at runtime:
+// unknown attributes, attributes which aren't allowed with the env var name (such as "prefix" on
+// a field which isn't a ">" nested config or "required" on a "-" field), tags on unexported
+// fields, env var names used by more than one field, and default values which can't be parsed
+// for the type of the field.
+//
+// To use it with go vet, build the cfgbuild-vet command and pass it as the vet tool:
+//
+//	go install github.com/NathanBak/cfgbuild/cmd/cfgbuild-vet
+//	go vet -vettool=$(which cfgbuild-vet) ./...
+package tagcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NathanBak/cfgbuild"
+	"github.com/NathanBak/cfgbuild/internal/timelayout"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `check cfgbuild struct tags
+
+Reports cfgbuild tags with unknown or misplaced attributes, tags on unexported fields, env var
+names used by more than one field, and default values which can't be parsed for the field type.`
+
+// Analyzer reports problems with cfgbuild struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "cfgbuildtags",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	tagKey            string
+	listSeparator     string
+	keyValueSeparator string
+	lenientBools      bool
+	basePrefixes      bool
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&tagKey, "tag", cfgbuild.DefaultTagKey,
+		"tag key (same as Builder.TagKey)")
+	Analyzer.Flags.StringVar(&listSeparator, "list-separator", cfgbuild.DefaultListSeparator,
+		"separator for list items in default values (same as Builder.ListSeparator)")
+	Analyzer.Flags.StringVar(&keyValueSeparator, "kv-separator", cfgbuild.DefaultKeyValueSeparator,
+		"separator for map keys and values in default values (same as Builder.KeyValueSeparator)")
+	Analyzer.Flags.BoolVar(&lenientBools, "lenient-bools", false,
+		"accept lenient boolean default values (same as Builder.BoolSyntax set to BoolLenient)")
+	Analyzer.Flags.BoolVar(&basePrefixes, "base-prefixes", false,
+		"accept integer default values with base prefixes (same as Builder.BasePrefixes)")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		styp, ok := pass.TypesInfo.TypeOf(st).(*types.Struct)
+		if !ok {
+			return
+		}
+		if checkFields(pass, st) {
+			checkDuplicates(pass, styp)
+		}
+	})
+	return nil, nil
+}
+
+// checkFields reports problems with the tags of the fields of a struct.  It returns false if
+// the struct doesn't have any tagged fields.
+func checkFields(pass *analysis.Pass, st *ast.StructType) bool {
+	tagged := false
+
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		tagValue, ok := reflect.StructTag(tag).Lookup(tagKey)
+		if !ok {
+			continue
+		}
+		tagged = true
+
+		names := field.Names
+		if len(names) == 0 {
+			// embedded field
+			names = []*ast.Ident{embeddedIdent(field.Type)}
+		}
+
+		for _, ident := range names {
+			if ident == nil {
+				continue
+			}
+			if !ident.IsExported() {
+				pass.Reportf(field.Pos(), "field %s: non-public fields may not have the tag set", ident.Name)
+				continue
+			}
+			if err := cfgbuild.ValidateTag(tagValue); err != nil {
+				pass.Reportf(field.Pos(), "field %s: %v", ident.Name, err)
+				continue
+			}
+
+			if err := checkDefault(pass, pass.TypesInfo.TypeOf(field.Type), tagValue); err != nil {
+				pass.Reportf(field.Pos(), "field %s: %v", ident.Name, err)
+			}
+		}
+	}
+	return tagged
+}
+
+// embeddedIdent returns the type name of an embedded field.
+func embeddedIdent(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embeddedIdent(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.IndexExpr:
+		return embeddedIdent(t.X)
+	case *ast.IndexListExpr:
+		return embeddedIdent(t.X)
+	}
+	return nil
+}
+
+// tagParts returns the env var names (the name followed by any aliases) and the attributes of
+// a tag value.
+func tagParts(tagValue string) ([]string, map[string]string) {
+	parts := strings.Split(tagValue, ",")
+	attrs := map[string]string{}
+	for _, part := range parts[1:] {
+		name, value, _ := strings.Cut(part, "=")
+		if _, ok := attrs[name]; !ok {
+			attrs[name] = value
+		}
+	}
+	return strings.Split(parts[0], "|"), attrs
+}
+
+// An envVarUse records the field which uses an env var name.
+type envVarUse struct {
+	path string
+	// top is the index of the field of the checked struct which leads to the field
+	top int
+}
+
+// checkDuplicates reports env var names which are used by more than one field of a struct
+// (including the fields of nested configs).
+func checkDuplicates(pass *analysis.Pass, styp *types.Struct) {
+	used := map[string]envVarUse{}
+	reported := map[int]bool{}
+
+	var walk func(s *types.Struct, prefix, path string, top int, stack map[*types.Struct]bool)
+	walk = func(s *types.Struct, prefix, path string, top int, stack map[*types.Struct]bool) {
+		if stack[s] {
+			return
+		}
+		stack[s] = true
+		defer delete(stack, s)
+
+		for i := 0; i < s.NumFields(); i++ {
+			field := s.Field(i)
+			tagValue, ok := reflect.StructTag(s.Tag(i)).Lookup(tagKey)
+			if !ok || cfgbuild.ValidateTag(tagValue) != nil {
+				continue
+			}
+			fieldTop := top
+			if fieldTop < 0 {
+				fieldTop = i
+			}
+			fieldPath := path + field.Name()
+
+			names, attrs := tagParts(tagValue)
+			switch names[0] {
+			case "-":
+				continue
+			case ">":
+				nested := field.Type()
+				if ptr, ok := nested.Underlying().(*types.Pointer); ok {
+					nested = ptr.Elem()
+				}
+				if ns, ok := nested.Underlying().(*types.Struct); ok {
+					// nested prefixes don't accumulate
+					walk(ns, attrs["prefix"], fieldPath+".", fieldTop, stack)
+				}
+				continue
+			}
+
+			for _, name := range names {
+				name = prefix + name
+				first, ok := used[name]
+				if !ok {
+					used[name] = envVarUse{path: fieldPath, top: fieldTop}
+					continue
+				}
+				if first.path == fieldPath || reported[fieldTop] {
+					continue
+				}
+				// Duplicates within a single nested config are reported for the nested struct.
+				if first.top == fieldTop && path != "" {
+					continue
+				}
+				reported[fieldTop] = true
+				pass.Reportf(styp.Field(fieldTop).Pos(), "field %s: duplicate env var name %q (also used by %s)",
+					fieldPath, name, first.path)
+			}
+		}
+	}
+
+	walk(styp, "", "", -1, map[*types.Struct]bool{})
+}
+
+// checkDefault returns an error if the tag has a default value which can't be parsed for the
+// field type.
+func checkDefault(pass *analysis.Pass, typ types.Type, tagValue string) error {
+	names, attrs := tagParts(tagValue)
+	defaultVal, ok := attrs["default"]
+	if !ok || names[0] == ">" || typ == nil {
+		return nil
+	}
+
+	// The default is decoded before it is parsed and the decoder may not be known
+	if _, ok := attrs["parser"]; ok {
+		return nil
+	}
+
+	// The default is the name of the file to read
+	if _, ok := attrs["file"]; ok {
+		return nil
+	}
+
+	if oneOf, ok := attrs["oneof"]; ok {
+		allowed := strings.Split(oneOf, "|")
+		found := false
+		for _, a := range allowed {
+			found = found || a == defaultVal
+		}
+		if !found {
+			return fmt.Errorf("default value %q is not one of %s", defaultVal, strings.Join(allowed, ", "))
+		}
+	}
+
+	if _, ok := attrs["hostport"]; ok {
+		vals := []string{defaultVal}
+		if _, ok := typ.Underlying().(*types.Slice); ok {
+			vals = strings.Split(defaultVal, listSeparator)
+		}
+		for _, v := range vals {
+			if _, _, err := net.SplitHostPort(strings.TrimSpace(v)); err != nil {
+				return fmt.Errorf("default value %q is not a host and port (%v)", defaultVal, err)
+			}
+		}
+	}
+
+	var err error
+	if _, ok := attrs["unmarshalJSON"]; ok {
+		if !json.Valid([]byte(defaultVal)) {
+			err = errors.New("invalid JSON")
+		}
+	} else if layout, ok := attrs["layout"]; ok {
+		_, err = timelayout.Parse(layout, defaultVal)
+	} else if _, ok := attrs["unit"]; ok {
+		_, err = cfgbuild.ParseByteSize(defaultVal)
+	} else {
+		err = parseValue(pass, typ, defaultVal, integerBase(typ, attrs))
+	}
+
+	if err != nil {
+		qualifier := func(pkg *types.Package) string {
+			if pkg == pass.Pkg {
+				return ""
+			}
+			return pkg.Name()
+		}
+		return fmt.Errorf("default value %q can't be parsed as %s (%v)", defaultVal,
+			types.TypeString(typ, qualifier), err)
+	}
+
+	if schemes, ok := attrs["schemes"]; ok {
+		allowed := strings.Split(strings.ToLower(schemes), "|")
+		if u, err := url.Parse(defaultVal); err == nil && !slices.Contains(allowed, u.Scheme) {
+			return fmt.Errorf("default value %q does not have one of the schemes %s", defaultVal,
+				strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// integerBase returns the base the Builder uses to parse the integers of a field of type typ.
+func integerBase(typ types.Type, attrs map[string]string) int {
+	if base, ok := attrs["base"]; ok {
+		// invalid values are reported by cfgbuild.ValidateTag
+		i, _ := strconv.Atoi(base)
+		return i
+	}
+
+	switch t := typ.(type) {
+	case *types.Pointer:
+		typ = t.Elem()
+	case *types.Slice:
+		typ = t.Elem()
+	}
+	if typeName(typ) == "io/fs.FileMode" {
+		return 8
+	}
+
+	if basePrefixes {
+		return 0
+	}
+	return 10
+}
+
+// prefixes are the prefixes (in lower case) which the Builder allows for the bases.
+var prefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// trimBasePrefix removes the prefix for the base from the integer s the same way as the Builder.
+func trimBasePrefix(s string, base int) string {
+	prefix, ok := prefixes[base]
+	if !ok {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		s = s[len(prefix):]
+	}
+	return sign + s
+}
+
+// parseValue returns an error if the Builder would be unable to parse s for the type.  It
+// follows the same order as the Builder: specific types, then TextUnmarshaler, and then the kind
+// of the type.  Types which can't be checked are ignored.
+func parseValue(pass *analysis.Pass, typ types.Type, s string, base int) error {
+	switch typeName(typ) {
+	case "time.Duration":
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			_, err = time.ParseDuration(s)
+			return err
+		}
+		return nil
+	case "time.Time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err
+	case "net/url.URL":
+		_, err := url.Parse(s)
+		return err
+	case "net.IPNet":
+		_, _, err := net.ParseCIDR(s)
+		return err
+	case "regexp.Regexp":
+		_, err := regexp.Compile(s)
+		return err
+	}
+
+	switch t := typ.(type) {
+	case *types.Slice:
+		if elem, ok := t.Elem().(*types.Basic); ok && elem.Kind() == types.Uint8 {
+			// []uint8 is treated as a series of bytes (unless Uint8Lists is set)
+			return nil
+		}
+		for _, v := range strings.Split(s, listSeparator) {
+			if err := parseValue(pass, t.Elem(), strings.TrimSpace(v), base); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *types.Map:
+		if types.Identical(t, types.NewMap(types.Typ[types.String], types.Typ[types.String])) {
+			for _, pair := range strings.Split(s, listSeparator) {
+				if len(strings.Split(pair, keyValueSeparator)) != 2 {
+					return fmt.Errorf("key/value pair must contain exactly one %q separator", keyValueSeparator)
+				}
+			}
+		}
+		return nil
+
+	case *types.Pointer:
+		// Pointers are parsed the same way as the element type
+		return parseValue(pass, t.Elem(), s, base)
+	}
+
+	if implementsTextUnmarshaler(typ) {
+		return nil
+	}
+	if basic, ok := typ.Underlying().(*types.Basic); ok {
+		return parseBasic(pass, basic, s, base)
+	}
+	return nil
+}
+
+// parseBasic returns an error if s can't be parsed as the basic type.
+func parseBasic(pass *analysis.Pass, basic *types.Basic, s string, base int) error {
+	bitSize := int(pass.TypesSizes.Sizeof(basic)) * 8
+	s = trimBasePrefix(s, base)
+
+	switch {
+	case basic.Info()&types.IsBoolean != 0:
+		switch strings.ToLower(s) {
+		case "true", "false":
+			return nil
+		case "1", "t", "yes", "on", "0", "f", "no", "off":
+			if lenientBools {
+				return nil
+			}
+		}
+		return fmt.Errorf("string %q is not a valid boolean value", s)
+
+	case basic.Info()&types.IsUnsigned != 0:
+		_, err := strconv.ParseUint(s, base, bitSize)
+		return unwrapNumError(err)
+
+	case basic.Info()&types.IsInteger != 0:
+		_, err := strconv.ParseInt(s, base, bitSize)
+		return unwrapNumError(err)
+
+	case basic.Info()&types.IsFloat != 0:
+		f, err := strconv.ParseFloat(s, 64)
+		if err == nil && bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+			err = errors.New("overflow error")
+		}
+		return unwrapNumError(err)
+	}
+	return nil
+}
+
+// unwrapNumError returns the underlying error of a strconv.NumError (ie "invalid syntax").
+func unwrapNumError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+// typeName returns the package path qualified name of a named type (or an empty string).
+func typeName(typ types.Type) string {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+// implementsTextUnmarshaler returns true if a pointer to the type has an UnmarshalText method.
+func implementsTextUnmarshaler(typ types.Type) bool {
+	mset := types.NewMethodSet(types.NewPointer(typ))
+	for i := 0; i < mset.Len(); i++ {
+		if mset.At(i).Obj().Name() == "UnmarshalText" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package a
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Good struct {
+	Name     string            `envvar:"NAME,default=app,oneof=app|web"`
+	Port     int               `envvar:"PORT,default=8080"`
+	Small    int8              `envvar:"SMALL,default=-128"`
+	Ratio    float32           `envvar:"RATIO,default=0.5"`
+	Enabled  bool              `envvar:"ENABLED,default=TRUE"`
+	Timeout  time.Duration     `envvar:"TIMEOUT,default=30s"`
+	Start    time.Time         `envvar:"START,default=2024-01-02T03:04:05Z"`
+	Day      time.Time         `envvar:"DAY,layout=DateOnly,default=2024-01-02"`
+	MaxBody  int               `envvar:"MAX_BODY,unit=bytes,default=10MB"`
+	Mode     os.FileMode       `envvar:"MODE,default=0644"`
+	RGB      uint32            `envvar:"RGB,base=16,default=ff8800"`
+	Offset   int               `envvar:"OFFSET,base=16,default=-0x1F"`
+	Mask     uint8             `envvar:"MASK,base=2,default=0b1010"`
+	Endpoint url.URL           `envvar:"ENDPOINT,default=http://localhost"`
+	Listen   string            `envvar:"LISTEN,hostport,default=:8080"`
+	Subnet   net.IPNet         `envvar:"SUBNET,default=10.0.0.0/8"`
+	Peers    []netip.AddrPort  `envvar:"PEERS,default=10.0.0.1:80"`
+	Filter   *regexp.Regexp    `envvar:"FILTER,default=^/api/"`
+	Callback url.URL           `envvar:"CALLBACK,schemes=http|https,default=https://example.com"`
+	Ports    []int             `envvar:"PORTS,file,default=/etc/app/ports"`
+	Counts   []int             `envvar:"COUNTS,default=1"`
+	Labels   map[string]string `envvar:"LABELS,default=a:b"`
+	Retries  *int              `envvar:"RETRIES,default=3"`
+	Color    Color             `envvar:"COLOR,default=red"`
+	Level    Level             `envvar:"LEVEL,default=3"`
+	Host     string            `envvar:"HOST|HOSTNAME,required"`
+	Limits   Limits            `envvar:"LIMITS,unmarshalJSON,default={}"`
+	Ignored  string            `envvar:"-"`
+	DB       DB                `envvar:">,prefix=DB_"`
+	internal string
+}
+
+type DB struct {
+	Host string `envvar:"HOST"`
+	Port int    `envvar:"PORT,default=5432"`
+}
+
+type Color int
+
+func (c *Color) UnmarshalText(text []byte) error {
+	*c = Color(len(strings.TrimSpace(string(text))))
+	return nil
+}
+
+type Level uint8
+
+type Limits struct {
+	Max int `json:"max"`
+}
+
+type Bad struct {
+	Port     int    `envvar:"PORT,requird"`              // want `field Port: tag value contains non-existent attribute "requird"`
+	Host     string `envvar:"HOST,prefix=X_"`            // want `field Host: the "prefix" attribute is only allowed on ">" nested config fields`
+	Skipped  string `envvar:"-,required"`                // want `field Skipped: the "required" attribute is not allowed on "-" fields`
+	hidden   string `envvar:"HIDDEN"`                    // want `field hidden: non-public fields may not have the tag set`
+	NoName   string `envvar:",default=x"`                // want `field NoName: tag does not have the name attribute set`
+	Nested   DB     `envvar:">,default=x"`               // want `field Nested: the "default" attribute is not allowed on ">" nested config fields`
+	Required string `envvar:"REQUIRED,required=yes"`     // want `field Required: the "required" attribute may not have a value`
+	Empty    string `envvar:"EMPTY,notempty,allowEmpty"` // want `field Empty: the "notempty" and "allowEmpty" attributes may not be used together`
+	Cert     string `envvar:"CERT,key=KEY,default=x"`    // want `field Cert: the "default" and "key" attributes may not be used together`
+}
+
+type BadDefaults struct {
+	Port     int               `envvar:"PORT,default=abc"`                 // want `field Port: default value "abc" can't be parsed as int \(invalid syntax\)`
+	Small    int8              `envvar:"SMALL,default=128"`                // want `field Small: default value "128" can't be parsed as int8 \(value out of range\)`
+	Count    uint              `envvar:"COUNT,default=-1"`                 // want `field Count: default value "-1" can't be parsed as uint \(invalid syntax\)`
+	Ratio    float32           `envvar:"RATIO,default=1e39"`               // want `field Ratio: default value "1e39" can't be parsed as float32 \(overflow error\)`
+	Enabled  bool              `envvar:"ENABLED,default=yes"`              // want `field Enabled: default value "yes" can't be parsed as bool \(string "yes" is not a valid boolean value\)`
+	Timeout  time.Duration     `envvar:"TIMEOUT,default=soon"`             // want `field Timeout: default value "soon" can't be parsed as time.Duration`
+	Start    time.Time         `envvar:"START,default=2024-01-02"`         // want `field Start: default value "2024-01-02" can't be parsed as time.Time`
+	Day      time.Time         `envvar:"DAY,layout=unix,default=today"`    // want `field Day: default value "today" can't be parsed as time.Time \(time "today" does not match the unix layout \(an integer\)\)`
+	MaxBody  int               `envvar:"MAX_BODY,unit=bytes,default=10XB"` // want `field MaxBody: default value "10XB" can't be parsed as int \(invalid byte size "10XB"\)`
+	DirMode  os.FileMode       `envvar:"DIR_MODE,default=0648"`            // want `field DirMode: default value "0648" can't be parsed as (os|fs).FileMode \(invalid syntax\)`
+	Mask     uint8             `envvar:"MASK,base=2,default=0x1"`          // want `field Mask: default value "0x1" can't be parsed as uint8 \(invalid syntax\)`
+	Counts   []int             `envvar:"COUNTS,default=1;2"`               // want `field Counts: default value "1;2" can't be parsed as \[\]int \(invalid syntax\)`
+	Labels   map[string]string `envvar:"LABELS,default=a"`                 // want `field Labels: default value "a" can't be parsed as map\[string\]string`
+	Retries  *int              `envvar:"RETRIES,default=x"`                // want `field Retries: default value "x" can't be parsed as \*int \(invalid syntax\)`
+	Level    Level             `envvar:"LEVEL,default=256"`                // want `field Level: default value "256" can't be parsed as Level \(value out of range\)`
+	Mode     string            `envvar:"MODE,default=fast,oneof=a|b"`      // want `field Mode: default value "fast" is not one of a, b`
+	Limits   Limits            `envvar:"LIMITS,unmarshalJSON,default={"`   // want `field Limits: default value "{" can't be parsed as Limits \(invalid JSON\)`
+	Color    Color             `envvar:"COLOR,default=anything"`
+	Endpoint url.URL           `envvar:"ENDPOINT,default=%zz"`                            // want `field Endpoint: default value "%zz" can't be parsed as url.URL`
+	Listen   string            `envvar:"LISTEN,hostport,default=localhost"`               // want `field Listen: default value "localhost" is not a host and port \(address localhost: missing port in address\)`
+	Filter   regexp.Regexp     `envvar:"FILTER,default=(api"`                             // want `field Filter: default value "\(api" can't be parsed as regexp.Regexp \(error parsing regexp: missing closing \)`
+	Callback url.URL           `envvar:"CALLBACK,schemes=https,default=http://localhost"` // want `field Callback: default value "http://localhost" does not have one of the schemes https`
+	Subnets  []*net.IPNet      `envvar:"SUBNETS,default=10.0.0.1"`                        // want `field Subnets: default value "10.0.0.1" can't be parsed as \[\]\*net.IPNet \(invalid CIDR address: 10.0.0.1\)`
+}
+
+type Duplicates struct {
+	Host    string `envvar:"HOST"`
+	Server  string `envvar:"SERVER|HOST"` // want `field Server: duplicate env var name "HOST" \(also used by Host\)`
+	DBHost  string `envvar:"DB_HOST"`
+	DB      DB     `envvar:">,prefix=DB_"` // want `field DB.Host: duplicate env var name "DB_HOST" \(also used by DBHost\)`
+	Primary DB     `envvar:">,prefix=PRIMARY_"`
+	Replica *DB    `envvar:">,prefix=PRIMARY_"` // want `field Replica.Host: duplicate env var name "PRIMARY_HOST" \(also used by Primary.Host\)`
+}
+
+type Recursive struct {
+	Name  string     `envvar:"NAME"`
+	Child *Recursive `envvar:">,prefix=CHILD_"`
+}
+
+func anonymous() interface{} {
+	return struct {
+		A int `envvar:"A,default=x"` // want `field A: default value "x" can't be parsed as int \(invalid syntax\)`
+	}{}
+}
+
+type Outer struct {
+	Inner DB `envvar:">,prefix=INNER_"`
+}
+
+// nested prefixes don't accumulate so Outer.Inner.Host is read from INNER_HOST
+type DeepDuplicates struct {
+	InnerHost string `envvar:"INNER_HOST"`
+	Outer     Outer  `envvar:">,prefix=OUTER_"` // want `field Outer.Inner.Host: duplicate env var name "INNER_HOST" \(also used by InnerHost\)`
+}
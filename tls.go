@@ -0,0 +1,162 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// mayHoldPrivateKey returns true if the value of the field may contain a private key (and so must
+// not be logged).  Certificate fields are always treated as holding a key.
+func mayHoldPrivateKey(f *fieldPlan, s string) bool {
+	return nestedConfigType(f.field.Type) == reflect.TypeOf(tls.Certificate{}) ||
+		strings.Contains(s, "PRIVATE KEY-----")
+}
+
+// parseCertificates returns the certificates in the PEM data.  Other PEM blocks (such as private
+// keys) are skipped so that a combined PEM can be used.
+func parseCertificates(s string) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return certs, nil
+}
+
+// parseCertPool returns a pool holding the certificates in the PEM data.
+func parseCertPool(s string) (*x509.CertPool, error) {
+	certs, err := parseCertificates(s)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// parseKeyPair returns the certificate chain and private key in the PEM data (which holds both the
+// certificates and the key).  The Leaf of the returned certificate is always set.
+func parseKeyPair(s string) (tls.Certificate, error) {
+	// Check the blocks first since the errors from X509KeyPair assume separate inputs
+	hasCert, hasKey := false, false
+	for rest := []byte(s); ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		hasCert = hasCert || block.Type == "CERTIFICATE"
+		hasKey = hasKey || strings.HasSuffix(block.Type, "PRIVATE KEY")
+	}
+	if !hasCert {
+		return tls.Certificate{}, errors.New("no PEM certificates found")
+	}
+	if !hasKey {
+		return tls.Certificate{}, errors.New("no PEM private key found")
+	}
+
+	cert, err := tls.X509KeyPair([]byte(s), []byte(s))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if cert.Leaf == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+	return cert, nil
+}
+
+// formatCertificates returns the PEM encoding of the certificates.
+func formatCertificates(certs []*x509.Certificate) string {
+	buf := []byte{}
+	for _, cert := range certs {
+		buf = append(buf, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return string(buf)
+}
+
+// readFile returns the contents of the file named by the value of a field with the "file"
+// attribute.  A single trailing newline is removed (the same as with a DirSource).
+func readFile(name string) (string, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(buf)), nil
+}
+
+// readKey returns the private key PEM from the env var named by the "key" attribute of the field
+// (read from a file if the field also has the "file" attribute).
+func (b *Builder[T]) readKey(f *fieldPlan) (string, error) {
+	key, _, ok, err := b.lookupEnvVar([]string{f.keyVar}, b.emptyPolicy(f))
+	if err != nil {
+		return "", err
+	}
+
+	keyError := func(err error) error {
+		return &ParseError{Name: b.Prefix + f.keyVar, Field: f.field.Name, Err: err}
+	}
+
+	if !ok {
+		return "", keyError(errors.New("the private key is not set"))
+	}
+	if f.file {
+		key, err = readFile(key)
+		if err != nil {
+			return "", keyError(err)
+		}
+	}
+	return key, nil
+}
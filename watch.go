@@ -0,0 +1,249 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2024, Nathan Bak
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+ 1. Redistributions of source code must retain the above copyright notice, this
+    list of conditions and the following disclaimer.
+
+ 2. Redistributions in binary form must reproduce the above copyright notice,
+    this list of conditions and the following disclaimer in the documentation
+    and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package cfgbuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultWatchInterval is the default time between checks for modified files.
+const DefaultWatchInterval = 5 * time.Second
+
+// A Watcher keeps a Config up to date by rebuilding it when the files backing the Builder Sources
+// change or when a signal (SIGHUP by default) is received.  A rebuilt Config only replaces the
+// current Config if the build (including CfgBuildValidate()) succeeds.
+type Watcher[T any] struct {
+	// Builder is used to build the Config.  Sources which implement WatchableSource are watched
+	// for changes.  If nil, a default Builder is used.
+	Builder *Builder[T]
+	// Paths are additional files or directories to watch for changes.
+	Paths []string
+	// Interval between checks for modified files.  Default is DefaultWatchInterval.
+	Interval time.Duration
+	// Signals which trigger a rebuild.  Default is SIGHUP.
+	Signals []os.Signal
+	// OnChange is called after a rebuilt Config with changed values replaces the current Config.
+	OnChange func(Change[T])
+	// OnError is called when a rebuild fails.  The current Config is left unchanged.
+	OnError func(error)
+
+	current atomic.Pointer[T]
+	mu      sync.Mutex
+}
+
+// A Change describes the replacement of the current Config by a rebuilt Config.
+type Change[T any] struct {
+	Old  T
+	New  T
+	Diff []FieldChange
+}
+
+// Current returns the current Config (or the zero value if the Config has not yet been built).
+func (w *Watcher[T]) Current() T {
+	if cfg := w.current.Load(); cfg != nil {
+		return *cfg
+	}
+	var zero T
+	return zero
+}
+
+// Reload rebuilds the Config.  If the build succeeds the rebuilt Config replaces the current
+// Config and, if any values changed, OnChange is called.  If a field with the "static" tag
+// attribute changed then a *RestartRequiredError is returned and the current Config is kept.
+func (w *Watcher[T]) Reload(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := w.builder()
+
+	oldPtr := w.current.Load()
+	if oldPtr == nil {
+		newCfg, err := b.fresh().BuildContext(ctx)
+		if err != nil {
+			return err
+		}
+		w.current.Store(&newCfg)
+		return nil
+	}
+
+	newCfg, diff, err := b.Reload(ctx, *oldPtr)
+	if err != nil {
+		return err
+	}
+	w.current.Store(&newCfg)
+
+	if len(diff) > 0 && w.OnChange != nil {
+		w.OnChange(Change[T]{Old: *oldPtr, New: newCfg, Diff: diff})
+	}
+	return nil
+}
+
+// Run builds the Config (if it has not already been built) and then watches for changes until
+// the context is done.  The initial build error is returned, but later errors are passed to
+// OnError.  When the context is done the context error is returned.
+func (w *Watcher[T]) Run(ctx context.Context) error {
+	if w.current.Load() == nil {
+		if err := w.Reload(ctx); err != nil {
+			return err
+		}
+	}
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	signals := w.Signals
+	if signals == nil {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	fingerprint := w.fingerprint()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sigCh:
+		case <-ticker.C:
+			latest := w.fingerprint()
+			if latest == fingerprint {
+				continue
+			}
+			fingerprint = latest
+		}
+
+		if err := w.Reload(ctx); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	}
+}
+
+func (w *Watcher[T]) builder() *Builder[T] {
+	if w.Builder == nil {
+		return &Builder[T]{}
+	}
+	return w.Builder
+}
+
+// Reload builds a new Config and compares it with the current (running) Config.  The new Config
+// and the list of changed fields are returned.  If any of the changed fields have the "static" tag
+// attribute then the changes cannot be applied without restarting and a *RestartRequiredError is
+// returned (along with the new Config and changes so the caller can decide how to proceed).
+func (b *Builder[T]) Reload(ctx context.Context, current T) (T, []FieldChange, error) {
+	newCfg, err := b.fresh().BuildContext(ctx)
+	if err != nil {
+		return newCfg, nil, err
+	}
+
+	diff := b.Diff(current, newCfg)
+
+	static := []FieldChange{}
+	for _, fc := range diff {
+		if fc.Static {
+			static = append(static, fc)
+		}
+	}
+	if len(static) > 0 {
+		return newCfg, diff, &RestartRequiredError{Changes: static}
+	}
+
+	return newCfg, diff, nil
+}
+
+// A RestartRequiredError is returned when reloading a Config would change fields with the "static"
+// tag attribute.
+type RestartRequiredError struct {
+	// Changes to the static fields.
+	Changes []FieldChange
+}
+
+func (e *RestartRequiredError) Error() string {
+	names := []string{}
+	for _, fc := range e.Changes {
+		names = append(names, fc.EnvVar)
+	}
+	return fmt.Sprintf("restart required to change %s", strings.Join(names, ", "))
+}
+
+// watchPaths returns the Paths and the paths of any WatchableSource.
+func (w *Watcher[T]) watchPaths() []string {
+	paths := append([]string{}, w.Paths...)
+	for _, src := range w.builder().Sources {
+		if ws, ok := src.(WatchableSource); ok {
+			paths = append(paths, ws.WatchPaths()...)
+		}
+	}
+	return paths
+}
+
+// fingerprint returns a string which changes when any of the watched files are modified.  The
+// entries of watched directories are included so changes to individual files (or the symlink
+// swap used when Kubernetes updates a mounted ConfigMap) are detected.
+func (w *Watcher[T]) fingerprint() string {
+	parts := []string{}
+	for _, path := range w.watchPaths() {
+		parts = append(parts, statFingerprint(path))
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parts = append(parts, statFingerprint(path+string(os.PathSeparator)+name))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func statFingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path + " " + err.Error()
+	}
+	return fmt.Sprintf("%s %d %d", path, info.ModTime().UnixNano(), info.Size())
+}